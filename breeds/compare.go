@@ -0,0 +1,181 @@
+package breeds
+
+import (
+	"math"
+	"strconv"
+
+	"myproject/catapi"
+)
+
+// Which way a trait is better, for picking comparison winners
+const (
+	HigherIsBetter = "higher"
+	LowerIsBetter  = "lower"
+)
+
+// lowerIsBetter lists ratings where less of the trait is preferable.
+var lowerIsBetter = map[string]bool{
+	"grooming":       true,
+	"health_issues":  true,
+	"shedding_level": true,
+}
+
+// ComparedBreed identifies a column of a Comparison.
+type ComparedBreed struct {
+	ID     string        `json:"id"`
+	Name   string        `json:"name"`
+	Origin string        `json:"origin"`
+	Image  *catapi.Image `json:"image,omitempty"`
+}
+
+// ComparisonRow compares one trait across the breeds, in column order.
+// Values are nil where a breed lacks the trait.
+type ComparisonRow struct {
+	Trait string `json:"trait"`
+	// Unit is "kg" or "years" for ranges, empty for 1-5 ratings.
+	Unit string `json:"unit,omitempty"`
+	// Better is HigherIsBetter, LowerIsBetter or empty when neither wins.
+	Better string `json:"better,omitempty"`
+	// Display holds the upstream text, e.g. "12 - 15", or the rating.
+	Display []string `json:"display"`
+	// Values are ratings or range midpoints.
+	Values []*float64 `json:"values"`
+	// Normalized scales Values to 0-1: ratings over 1-5 and ranges
+	// against the largest value compared.
+	Normalized []*float64 `json:"normalized"`
+	// Differences are each value's distance from the best one, or from the
+	// largest when there is no better direction.
+	Differences []*float64 `json:"differences"`
+	// Spread is the gap between the largest and smallest value.
+	Spread float64 `json:"spread"`
+	// Winners are the IDs of the breeds with the best value, several on a
+	// tie. It is empty when Better is empty or no breed stands out.
+	Winners []string `json:"winners"`
+}
+
+// Comparison is a trait-by-breed table.
+type Comparison struct {
+	Breeds []ComparedBreed `json:"breeds"`
+	Rows   []ComparisonRow `json:"rows"`
+	// Wins counts the rows each breed wins, by ID.
+	Wins map[string]int `json:"wins"`
+}
+
+// Compare builds the comparison table for list, one column per breed:
+// weight, life span and then every rating at least one breed has.
+func Compare(list []catapi.Breed) Comparison {
+	cmp := Comparison{Breeds: make([]ComparedBreed, 0, len(list)), Wins: map[string]int{}}
+	for _, b := range list {
+		cmp.Breeds = append(cmp.Breeds, ComparedBreed{ID: b.ID, Name: b.Name, Origin: b.Origin, Image: b.Image})
+		cmp.Wins[b.ID] = 0
+	}
+
+	cmp.Rows = append(cmp.Rows,
+		rangeRow(list, "weight", "kg", "", func(b catapi.Breed) (string, float64, float64, bool) {
+			low, high, ok := b.Weight.MetricKilograms()
+			return b.Weight.Metric, low, high, ok
+		}),
+		rangeRow(list, "life_span", "years", HigherIsBetter, func(b catapi.Breed) (string, float64, float64, bool) {
+			low, high, ok := b.LifeSpanYears()
+			return b.LifeSpan, low, high, ok
+		}),
+	)
+
+	for _, name := range catapi.RatingNames {
+		row := ComparisonRow{Trait: name, Better: HigherIsBetter}
+		if lowerIsBetter[name] {
+			row.Better = LowerIsBetter
+		}
+		rated := false
+		for _, b := range list {
+			level, _ := b.Rating(name)
+			if level == 0 {
+				row.Display = append(row.Display, "")
+				row.Values = append(row.Values, nil)
+				row.Normalized = append(row.Normalized, nil)
+				continue
+			}
+			rated = true
+			row.Display = append(row.Display, strconv.Itoa(level))
+			row.Values = append(row.Values, ptr(float64(level)))
+			row.Normalized = append(row.Normalized, ptr(float64(level-1)/4))
+		}
+		if !rated {
+			continue
+		}
+		finishRow(&row, list)
+		cmp.Rows = append(cmp.Rows, row)
+	}
+
+	for _, row := range cmp.Rows {
+		for _, id := range row.Winners {
+			cmp.Wins[id]++
+		}
+	}
+	return cmp
+}
+
+// rangeRow builds a row from a "low - high" attribute using its midpoint
+func rangeRow(list []catapi.Breed, trait, unit, better string, get func(catapi.Breed) (string, float64, float64, bool)) ComparisonRow {
+	row := ComparisonRow{Trait: trait, Unit: unit, Better: better}
+	var largest float64
+	for _, b := range list {
+		display, low, high, ok := get(b)
+		row.Display = append(row.Display, display)
+		if !ok {
+			row.Values = append(row.Values, nil)
+			continue
+		}
+		mid := (low + high) / 2
+		row.Values = append(row.Values, ptr(mid))
+		largest = math.Max(largest, mid)
+	}
+	for _, v := range row.Values {
+		if v == nil || largest == 0 {
+			row.Normalized = append(row.Normalized, nil)
+			continue
+		}
+		row.Normalized = append(row.Normalized, ptr(round(*v/largest)))
+	}
+	finishRow(&row, list)
+	return row
+}
+
+// finishRow fills in Spread, Differences and Winners from Values
+func finishRow(row *ComparisonRow, list []catapi.Breed) {
+	lowest, highest := math.Inf(1), math.Inf(-1)
+	for _, v := range row.Values {
+		if v != nil {
+			lowest, highest = math.Min(lowest, *v), math.Max(highest, *v)
+		}
+	}
+	row.Winners = []string{}
+	if math.IsInf(lowest, 1) {
+		row.Differences = make([]*float64, len(row.Values))
+		return
+	}
+	row.Spread = round(highest - lowest)
+
+	best := highest
+	if row.Better == LowerIsBetter {
+		best = lowest
+	}
+	for i, v := range row.Values {
+		if v == nil {
+			row.Differences = append(row.Differences, nil)
+			continue
+		}
+		row.Differences = append(row.Differences, ptr(round(math.Abs(best-*v))))
+		if row.Better != "" && row.Spread > 0 && *v == best {
+			row.Winners = append(row.Winners, list[i].ID)
+		}
+	}
+}
+
+func ptr(v float64) *float64 {
+	return &v
+}
+
+func round(v float64) float64 {
+	return math.Round(v*100) / 100
+}
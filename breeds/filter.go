@@ -0,0 +1,186 @@
+// Package breeds filters, sorts and ranks the breed list fetched from the
+// Cat API.
+package breeds
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"myproject/catapi"
+)
+
+// Sort orders accepted by Filter.
+const (
+	SortName     = "name"
+	SortOrigin   = "origin"
+	SortLifeSpan = "life_span"
+)
+
+// Filter narrows and orders a breed list. The zero Filter keeps every
+// breed in upstream order.
+type Filter struct {
+	// Origin matches the breed's origin, ignoring case.
+	Origin string
+	// Temperament lists words that must all appear in the temperament.
+	Temperament []string
+	// Flags maps flag names (see catapi.FlagNames) to the required value.
+	Flags map[string]bool
+	// Min and Max bound trait levels by name (see catapi.RatingNames).
+	Min, Max map[string]int
+	// Sort is one of SortName, SortOrigin or SortLifeSpan, or empty.
+	Sort string
+}
+
+// ParseFilter reads a Filter from query parameters: origin, temperament
+// (comma separated), any flag name as 0/1, min_<trait> and max_<trait>
+// from 1 to 5, and sort. Unrelated parameters are ignored.
+func ParseFilter(q url.Values) (Filter, error) {
+	f := Filter{Origin: strings.TrimSpace(q.Get("origin"))}
+
+	for _, t := range strings.Split(q.Get("temperament"), ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			f.Temperament = append(f.Temperament, t)
+		}
+	}
+
+	for _, name := range catapi.FlagNames {
+		v := q.Get(name)
+		if v == "" {
+			continue
+		}
+		set, err := strconv.ParseBool(v)
+		if err != nil {
+			return Filter{}, fmt.Errorf("%s must be 0 or 1", name)
+		}
+		if f.Flags == nil {
+			f.Flags = map[string]bool{}
+		}
+		f.Flags[name] = set
+	}
+
+	for _, name := range catapi.RatingNames {
+		for _, bound := range []string{"min", "max"} {
+			key := bound + "_" + name
+			v := q.Get(key)
+			if v == "" {
+				continue
+			}
+			level, err := strconv.Atoi(v)
+			if err != nil || level < 1 || level > 5 {
+				return Filter{}, fmt.Errorf("%s must be between 1 and 5", key)
+			}
+			if bound == "min" {
+				if f.Min == nil {
+					f.Min = map[string]int{}
+				}
+				f.Min[name] = level
+			} else {
+				if f.Max == nil {
+					f.Max = map[string]int{}
+				}
+				f.Max[name] = level
+			}
+		}
+	}
+
+	switch sortBy := q.Get("sort"); sortBy {
+	case "", SortName, SortOrigin, SortLifeSpan:
+		f.Sort = sortBy
+	default:
+		return Filter{}, fmt.Errorf("sort must be %s, %s or %s", SortName, SortOrigin, SortLifeSpan)
+	}
+	return f, nil
+}
+
+// Match reports whether breed passes every condition of f.
+func (f Filter) Match(breed catapi.Breed) bool {
+	if f.Origin != "" && !strings.EqualFold(f.Origin, breed.Origin) {
+		return false
+	}
+
+	if len(f.Temperament) > 0 {
+		traits := temperaments(breed)
+		for _, want := range f.Temperament {
+			if !traits[strings.ToLower(want)] {
+				return false
+			}
+		}
+	}
+
+	for name, want := range f.Flags {
+		if set, _ := breed.Flag(name); set != want {
+			return false
+		}
+	}
+	for name, min := range f.Min {
+		if level, _ := breed.Rating(name); level < min {
+			return false
+		}
+	}
+	for name, max := range f.Max {
+		// Unrated traits can't be shown to be under the limit
+		if level, _ := breed.Rating(name); level == 0 || level > max {
+			return false
+		}
+	}
+	return true
+}
+
+// Apply returns the breeds matching f in f's order. list is not modified.
+func (f Filter) Apply(list []catapi.Breed) []catapi.Breed {
+	out := make([]catapi.Breed, 0, len(list))
+	for _, b := range list {
+		if f.Match(b) {
+			out = append(out, b)
+		}
+	}
+
+	switch f.Sort {
+	case SortName:
+		sort.SliceStable(out, func(i, j int) bool { return lessFold(out[i].Name, out[j].Name) })
+	case SortOrigin:
+		sort.SliceStable(out, func(i, j int) bool {
+			if !strings.EqualFold(out[i].Origin, out[j].Origin) {
+				return lessFold(out[i].Origin, out[j].Origin)
+			}
+			return lessFold(out[i].Name, out[j].Name)
+		})
+	case SortLifeSpan:
+		// Longest-lived first; breeds without a life span go last
+		sort.SliceStable(out, func(i, j int) bool {
+			li, lok := meanLifeSpan(out[i])
+			lj, rok := meanLifeSpan(out[j])
+			if lok != rok {
+				return lok
+			}
+			if li != lj {
+				return li > lj
+			}
+			return lessFold(out[i].Name, out[j].Name)
+		})
+	}
+	return out
+}
+
+// temperaments returns the lower-cased words of the breed's temperament
+func temperaments(breed catapi.Breed) map[string]bool {
+	set := map[string]bool{}
+	for _, t := range strings.Split(breed.Temperament, ",") {
+		if t = strings.ToLower(strings.TrimSpace(t)); t != "" {
+			set[t] = true
+		}
+	}
+	return set
+}
+
+func meanLifeSpan(breed catapi.Breed) (float64, bool) {
+	low, high, ok := breed.LifeSpanYears()
+	return (low + high) / 2, ok
+}
+
+func lessFold(a, b string) bool {
+	return strings.ToLower(a) < strings.ToLower(b)
+}
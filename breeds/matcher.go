@@ -0,0 +1,232 @@
+package breeds
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+
+	"myproject/catapi"
+)
+
+// Questionnaire answers accepted by Answers.
+var (
+	ApartmentSizes = []string{"small", "medium", "large"}
+	TimesAtHome    = []string{"little", "some", "lots"}
+	OtherPets      = []string{"dogs", "cats"}
+)
+
+// Answers are a prospective owner's lifestyle. Empty fields and nil
+// pointers are questions left unanswered.
+type Answers struct {
+	ApartmentSize string   `json:"apartment_size,omitempty"`
+	Allergies     *bool    `json:"allergies,omitempty"`
+	TimeAtHome    string   `json:"time_at_home,omitempty"`
+	Kids          *bool    `json:"kids,omitempty"`
+	OtherPets     []string `json:"other_pets,omitempty"`
+}
+
+// Validate checks every answer is one of the accepted values and that the
+// answers narrow the match. Answering no to allergies and kids alone says
+// nothing about the breed, so it is rejected too.
+func (a Answers) Validate() error {
+	if a.ApartmentSize != "" && !slices.Contains(ApartmentSizes, a.ApartmentSize) {
+		return fmt.Errorf("apartment_size must be one of %s", strings.Join(ApartmentSizes, ", "))
+	}
+	if a.TimeAtHome != "" && !slices.Contains(TimesAtHome, a.TimeAtHome) {
+		return fmt.Errorf("time_at_home must be one of %s", strings.Join(TimesAtHome, ", "))
+	}
+	for _, pet := range a.OtherPets {
+		if !slices.Contains(OtherPets, pet) {
+			return fmt.Errorf("other_pets may only list %s", strings.Join(OtherPets, ", "))
+		}
+	}
+	if a.ApartmentSize == "" && a.Allergies == nil && a.TimeAtHome == "" && a.Kids == nil && len(a.OtherPets) == 0 {
+		return fmt.Errorf("answer at least one question")
+	}
+	if len(a.criteria()) == 0 {
+		return fmt.Errorf("answer apartment_size, time_at_home or other_pets, or yes to allergies or kids")
+	}
+	return nil
+}
+
+// Weights say how much each question counts towards a match, keyed by
+// the question's JSON name. Questions missing from Weights count as 1.
+type Weights map[string]float64
+
+// DefaultWeights make allergies decisive and the rest roughly equal.
+var DefaultWeights = Weights{
+	"apartment_size": 1,
+	"allergies":      3,
+	"time_at_home":   1.5,
+	"kids":           2,
+	"other_pets":     1.5,
+}
+
+// criterion asks for a trait level, on behalf of a question
+type criterion struct {
+	question string
+	trait    string
+	ideal    int
+	reason   string
+}
+
+// criteria turns the answers into the trait levels a good match has.
+func (a Answers) criteria() []criterion {
+	var out []criterion
+	add := func(question, trait string, ideal int, reason string) {
+		out = append(out, criterion{question, trait, ideal, reason})
+	}
+
+	switch a.ApartmentSize {
+	case "small":
+		add("apartment_size", "energy_level", 2, "calm enough for a small home")
+		add("apartment_size", "adaptability", 5, "adapts to tight spaces")
+		add("apartment_size", "vocalisation", 1, "quiet around neighbours")
+	case "medium":
+		add("apartment_size", "energy_level", 3, "moderate energy for a medium home")
+		add("apartment_size", "adaptability", 4, "adapts to indoor life")
+	case "large":
+		add("apartment_size", "energy_level", 5, "has room to burn energy")
+	}
+
+	if a.Allergies != nil && *a.Allergies {
+		add("allergies", "hypoallergenic", 5, "hypoallergenic")
+		add("allergies", "shedding_level", 1, "sheds little")
+	}
+
+	switch a.TimeAtHome {
+	case "little":
+		add("time_at_home", "social_needs", 1, "copes with time alone")
+		add("time_at_home", "grooming", 1, "needs little grooming")
+	case "some":
+		add("time_at_home", "social_needs", 3, "moderate need for company")
+	case "lots":
+		add("time_at_home", "social_needs", 5, "thrives on company")
+		add("time_at_home", "affection_level", 5, "very affectionate")
+	}
+
+	if a.Kids != nil && *a.Kids {
+		add("kids", "child_friendly", 5, "good with children")
+	}
+
+	for _, pet := range a.OtherPets {
+		switch pet {
+		case "dogs":
+			add("other_pets", "dog_friendly", 5, "gets along with dogs")
+		case "cats":
+			add("other_pets", "cat_friendly", 5, "gets along with other cats")
+		}
+	}
+	return out
+}
+
+// ScoreItem is one trait's part in a breed's match score.
+type ScoreItem struct {
+	Question string `json:"question"`
+	Trait    string `json:"trait"`
+	Level    int    `json:"level"`
+	Ideal    int    `json:"ideal"`
+	// Weight is the item's share of its question's weight.
+	Weight float64 `json:"weight"`
+	// Fit is how close Level is to Ideal, from 0 to 1.
+	Fit float64 `json:"fit"`
+	// Points are the percentage points this item adds to the score.
+	Points float64 `json:"points"`
+	Reason string  `json:"reason"`
+}
+
+// Match is a recommended breed with a score from 0 to 100 and the items
+// behind it, the biggest contributors first.
+type Match struct {
+	ID        string        `json:"id"`
+	Name      string        `json:"name"`
+	Origin    string        `json:"origin"`
+	Image     *catapi.Image `json:"image,omitempty"`
+	Score     float64       `json:"score"`
+	Breakdown []ScoreItem   `json:"breakdown"`
+}
+
+// Recommend scores every breed against the answers and returns up to limit
+// of them, best first. Traits a breed is not rated on are left out of its
+// score rather than counted against it.
+func Recommend(list []catapi.Breed, answers Answers, weights Weights, limit int) []Match {
+	criteria := answers.criteria()
+
+	matches := make([]Match, 0, len(list))
+	for _, b := range list {
+		// A question's weight is shared by its traits the breed is rated
+		// on, so asking about more traits doesn't make it count more
+		levels := make([]int, len(criteria))
+		rated := map[string]int{}
+		for i, c := range criteria {
+			if level, ok := traitLevel(b, c.trait); ok {
+				levels[i] = level
+				rated[c.question]++
+			}
+		}
+
+		var items []ScoreItem
+		var total float64
+		for i, c := range criteria {
+			level := levels[i]
+			if level == 0 {
+				continue
+			}
+			weight, ok := weights[c.question]
+			if !ok {
+				weight = 1
+			}
+			if weight <= 0 {
+				continue
+			}
+			weight /= float64(rated[c.question])
+			fit := 1 - math.Abs(float64(level-c.ideal))/4
+			items = append(items, ScoreItem{
+				Question: c.question, Trait: c.trait, Level: level, Ideal: c.ideal,
+				Weight: weight, Fit: round(fit), Reason: c.reason,
+			})
+			total += weight
+		}
+
+		m := Match{ID: b.ID, Name: b.Name, Origin: b.Origin, Image: b.Image, Breakdown: []ScoreItem{}}
+		if total == 0 {
+			matches = append(matches, m)
+			continue
+		}
+		for _, item := range items {
+			item.Points = round(item.Fit * item.Weight / total * 100)
+			m.Score += item.Fit * item.Weight / total * 100
+			item.Weight = round(item.Weight)
+			m.Breakdown = append(m.Breakdown, item)
+		}
+		m.Score = round(m.Score)
+		sort.SliceStable(m.Breakdown, func(i, j int) bool { return m.Breakdown[i].Points > m.Breakdown[j].Points })
+		matches = append(matches, m)
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].Score != matches[j].Score {
+			return matches[i].Score > matches[j].Score
+		}
+		return lessFold(matches[i].Name, matches[j].Name)
+	})
+	if limit > 0 && len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches
+}
+
+// traitLevel reads a rating, or a flag as 5 when set and 1 when not.
+// ok is false for unrated traits.
+func traitLevel(b catapi.Breed, trait string) (int, bool) {
+	if set, ok := b.Flag(trait); ok {
+		if set {
+			return 5, true
+		}
+		return 1, true
+	}
+	level, _ := b.Rating(trait)
+	return level, level > 0
+}
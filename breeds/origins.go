@@ -0,0 +1,66 @@
+package breeds
+
+import (
+	"sort"
+	"strings"
+
+	"myproject/catapi"
+)
+
+// OriginBreed is a breed listed under an Origin.
+type OriginBreed struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// Origin groups the breeds from one country.
+type Origin struct {
+	Country     string        `json:"country"`
+	CountryCode string        `json:"country_code,omitempty"`
+	Count       int           `json:"count"`
+	Breeds      []OriginBreed `json:"breeds"`
+	// Image is the reference image of the first breed, by name, that has one.
+	Image *catapi.Image `json:"image,omitempty"`
+}
+
+// ByOrigin groups list by country code, or by origin for breeds without
+// one. Countries with the most breeds come first, then by name.
+func ByOrigin(list []catapi.Breed) []Origin {
+	sorted := make([]catapi.Breed, len(list))
+	copy(sorted, list)
+	sort.SliceStable(sorted, func(i, j int) bool { return lessFold(sorted[i].Name, sorted[j].Name) })
+
+	var out []*Origin
+	byKey := map[string]*Origin{}
+	for _, b := range sorted {
+		country := strings.TrimSpace(b.Origin)
+		code := strings.ToUpper(strings.TrimSpace(b.CountryCode))
+		key := code
+		if key == "" {
+			key = strings.ToLower(country)
+		}
+		o, ok := byKey[key]
+		if !ok {
+			o = &Origin{Country: country, CountryCode: code, Breeds: []OriginBreed{}}
+			byKey[key] = o
+			out = append(out, o)
+		}
+		o.Count++
+		o.Breeds = append(o.Breeds, OriginBreed{ID: b.ID, Name: b.Name})
+		if o.Image == nil && b.Image != nil {
+			o.Image = b.Image
+		}
+	}
+
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].Count != out[j].Count {
+			return out[i].Count > out[j].Count
+		}
+		return lessFold(out[i].Country, out[j].Country)
+	})
+	origins := make([]Origin, 0, len(out))
+	for _, o := range out {
+		origins = append(origins, *o)
+	}
+	return origins
+}
@@ -0,0 +1,278 @@
+package breeds
+
+import (
+	"context"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"myproject/catapi"
+)
+
+// Suggestion is a breed matching a search, best first.
+type Suggestion struct {
+	ID     string  `json:"id"`
+	Name   string  `json:"name"`
+	Origin string  `json:"origin"`
+	Field  string  `json:"field"`
+	Match  string  `json:"match"`
+	Score  float64 `json:"score"`
+}
+
+// Fields searched by an Index, with how much a match in each counts.
+var fieldWeights = []struct {
+	name   string
+	weight float64
+}{
+	{"name", 1.0},
+	{"alt_names", 0.9},
+	{"origin", 0.7},
+	{"temperament", 0.5},
+}
+
+// Match qualities, scaled by the field weight
+const (
+	exactMatch  = 1.0
+	prefixMatch = 0.85
+	substrMatch = 0.6
+	typoMatch   = 0.5
+)
+
+type indexedWord struct {
+	word  string
+	field int
+	text  string
+}
+
+type indexedBreed struct {
+	breed catapi.Breed
+	words []indexedWord
+}
+
+// Index answers typo-tolerant searches over breed names, alternate names,
+// origins and temperaments. It is immutable and safe for concurrent use.
+type Index struct {
+	breeds []indexedBreed
+}
+
+// NewIndex builds an index over list.
+func NewIndex(list []catapi.Breed) *Index {
+	idx := &Index{breeds: make([]indexedBreed, 0, len(list))}
+	for _, b := range list {
+		entry := indexedBreed{breed: b}
+		for field, text := range []string{b.Name, b.AltNames, b.Origin, b.Temperament} {
+			for _, part := range strings.Split(text, ",") {
+				part = strings.TrimSpace(part)
+				for _, w := range words(part) {
+					entry.words = append(entry.words, indexedWord{word: w, field: field, text: part})
+				}
+			}
+		}
+		idx.breeds = append(idx.breeds, entry)
+	}
+	return idx
+}
+
+// Len returns the number of indexed breeds.
+func (idx *Index) Len() int {
+	return len(idx.breeds)
+}
+
+// Search returns up to limit breeds matching every word of q, best first.
+// A limit of zero or less returns all matches.
+func (idx *Index) Search(q string, limit int) []Suggestion {
+	terms := words(q)
+	if len(terms) == 0 {
+		return []Suggestion{}
+	}
+
+	out := []Suggestion{}
+	for _, entry := range idx.breeds {
+		var total float64
+		var best indexedWord
+		var bestScore float64
+		for _, term := range terms {
+			score, word := entry.bestMatch(term)
+			if score == 0 {
+				total = 0
+				break
+			}
+			total += score
+			if score > bestScore {
+				bestScore, best = score, word
+			}
+		}
+		if total == 0 {
+			continue
+		}
+
+		// Reward the name starting with the whole query
+		if strings.HasPrefix(strings.ToLower(entry.breed.Name), strings.Join(terms, " ")) {
+			total += 0.5
+		}
+		out = append(out, Suggestion{
+			ID:     entry.breed.ID,
+			Name:   entry.breed.Name,
+			Origin: entry.breed.Origin,
+			Field:  fieldWeights[best.field].name,
+			Match:  best.text,
+			Score:  math.Round(total/float64(len(terms))*1000) / 1000,
+		})
+	}
+
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].Score != out[j].Score {
+			return out[i].Score > out[j].Score
+		}
+		return lessFold(out[i].Name, out[j].Name)
+	})
+	if limit > 0 && len(out) > limit {
+		out = out[:limit]
+	}
+	return out
+}
+
+// bestMatch scores term against every word of the breed and returns the
+// best one. A score of zero means no match.
+func (e indexedBreed) bestMatch(term string) (float64, indexedWord) {
+	var best float64
+	var bestWord indexedWord
+	for _, w := range e.words {
+		quality := matchQuality(term, w.word)
+		if quality == 0 {
+			continue
+		}
+		if score := quality * fieldWeights[w.field].weight; score > best {
+			best, bestWord = score, w
+		}
+	}
+	return best, bestWord
+}
+
+func matchQuality(term, word string) float64 {
+	switch {
+	case term == word:
+		return exactMatch
+	case strings.HasPrefix(word, term):
+		return prefixMatch
+	case len(term) >= 3 && strings.Contains(word, term):
+		return substrMatch
+	}
+
+	// Allow one typo from four letters and two from eight, against the
+	// whole word or a same-length prefix of it while the user is typing
+	allowed := 0
+	switch n := len([]rune(term)); {
+	case n >= 8:
+		allowed = 2
+	case n >= 4:
+		allowed = 1
+	}
+	if allowed == 0 {
+		return 0
+	}
+	d := editDistance(term, word, allowed)
+	if prefix := runePrefix(word, len([]rune(term))); prefix != word {
+		d = min(d, editDistance(term, prefix, allowed))
+	}
+	if d > allowed {
+		return 0
+	}
+	return typoMatch - 0.1*float64(d-1)
+}
+
+// editDistance returns the edit distance of a and b, counting a swap of
+// adjacent letters as one edit, or limit+1 once it is known to exceed limit.
+func editDistance(a, b string, limit int) int {
+	ra, rb := []rune(a), []rune(b)
+	if diff := len(ra) - len(rb); diff > limit || -diff > limit {
+		return limit + 1
+	}
+
+	prev2 := make([]int, len(rb)+1)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		rowMin := cur[0]
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				cur[j] = min(cur[j], prev2[j-2]+1)
+			}
+			rowMin = min(rowMin, cur[j])
+		}
+		if rowMin > limit {
+			return limit + 1
+		}
+		prev2, prev, cur = prev, cur, prev2
+	}
+	return prev[len(rb)]
+}
+
+func runePrefix(s string, n int) string {
+	r := []rune(s)
+	if n >= len(r) {
+		return s
+	}
+	return string(r[:n])
+}
+
+// words splits s into lower-cased letter and digit runs
+func words(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// Indexer keeps an Index over a breed source and rebuilds it once it is
+// older than the refresh interval, or on every use while the source
+// answers with stale data. It is safe for concurrent use.
+type Indexer struct {
+	source  func(context.Context) ([]catapi.Breed, catapi.CacheStatus, error)
+	refresh time.Duration
+	now     func() time.Time
+
+	mu      sync.Mutex
+	index   *Index
+	status  catapi.CacheStatus
+	builtAt time.Time
+}
+
+// NewIndexer returns an Indexer over source rebuilt every refresh.
+func NewIndexer(source func(context.Context) ([]catapi.Breed, catapi.CacheStatus, error), refresh time.Duration) *Indexer {
+	return &Indexer{source: source, refresh: refresh, now: time.Now}
+}
+
+// Index returns the current index and how the source answered when it was
+// built, rebuilding it when due. If a rebuild fails the previous index
+// keeps being served as stale.
+func (x *Indexer) Index(ctx context.Context) (*Index, catapi.CacheStatus, error) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	now := x.now()
+	if x.index != nil && x.status != catapi.CacheStale && now.Sub(x.builtAt) < x.refresh {
+		return x.index, catapi.CacheHit, nil
+	}
+
+	list, status, err := x.source(ctx)
+	if err != nil {
+		if x.index != nil {
+			return x.index, catapi.CacheStale, nil
+		}
+		return nil, "", err
+	}
+	x.index, x.status, x.builtAt = NewIndex(list), status, now
+	return x.index, status, nil
+}
@@ -0,0 +1,195 @@
+package catapi
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// CacheStatus says how a BreedCache lookup was answered. It is suitable
+// for an X-Cache response header.
+type CacheStatus string
+
+const (
+	// CacheHit means the list was fresh and no request was made.
+	CacheHit CacheStatus = "HIT"
+	// CacheMiss means the list was downloaded.
+	CacheMiss CacheStatus = "MISS"
+	// CacheRevalidated means the upstream confirmed the cached list is current.
+	CacheRevalidated CacheStatus = "REVALIDATED"
+	// CacheStale means the upstream failed and an expired list, or the
+	// snapshot given to WithSnapshot, was served.
+	CacheStale CacheStatus = "STALE"
+)
+
+// staleRetryInterval bounds how often a failing upstream is retried while
+// stale data is being served.
+const staleRetryInterval = 10 * time.Second
+
+// CacheStats are the counters of a BreedCache.
+type CacheStats struct {
+	Hits        int64     `json:"hits"`
+	Misses      int64     `json:"misses"`
+	Revalidated int64     `json:"revalidated"`
+	Stale       int64     `json:"stale"`
+	Errors      int64     `json:"errors"`
+	Breeds      int       `json:"breeds"`
+	FetchedAt   time.Time `json:"fetched_at"`
+	ExpiresAt   time.Time `json:"expires_at"`
+	// Snapshot is set while the list comes from the WithSnapshot fallback,
+	// in which case FetchedAt is when the snapshot was taken.
+	Snapshot bool `json:"snapshot"`
+}
+
+// BreedCache keeps the breed list in memory for a TTL. Once expired the
+// list is revalidated with its ETag, and if the upstream fails the
+// expired list keeps being served. Before the first successful download a
+// snapshot given to WithSnapshot is served instead. It is safe for
+// concurrent use.
+type BreedCache struct {
+	client *Client
+	ttl    time.Duration
+	now    func() time.Time
+
+	mu        sync.Mutex
+	breeds    []Breed
+	etag      string
+	loaded    bool
+	stale     bool
+	fetchedAt time.Time
+	expiresAt time.Time
+
+	snapshot     []Breed
+	snapshotAt   time.Time
+	fromSnapshot bool
+
+	hits, misses, revalidated, staleServed, errors int64
+}
+
+// CacheOption configures a BreedCache.
+type CacheOption func(*BreedCache)
+
+// WithCacheClock overrides the clock used for expiry, for tests.
+func WithCacheClock(now func() time.Time) CacheOption {
+	return func(c *BreedCache) {
+		c.now = now
+	}
+}
+
+// WithSnapshot sets a breed list, taken at takenAt, to serve as stale
+// while the upstream cannot be reached and nothing has been downloaded.
+func WithSnapshot(breeds []Breed, takenAt time.Time) CacheOption {
+	return func(c *BreedCache) {
+		c.snapshot, c.snapshotAt = breeds, takenAt
+	}
+}
+
+// NewBreedCache returns an empty cache over client. A ttl of zero or less
+// revalidates on every lookup.
+func NewBreedCache(client *Client, ttl time.Duration, opts ...CacheOption) *BreedCache {
+	c := &BreedCache{client: client, ttl: ttl, now: time.Now}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Breeds returns a copy of the breed list and how it was obtained.
+func (c *BreedCache) Breeds(ctx context.Context) ([]Breed, CacheStatus, error) {
+	list, status, err := c.refresh(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+	breeds := make([]Breed, len(list))
+	copy(breeds, list)
+	return breeds, status, nil
+}
+
+// Breed returns the breed with id. ok is false when no such breed exists.
+func (c *BreedCache) Breed(ctx context.Context, id string) (breed Breed, ok bool, status CacheStatus, err error) {
+	list, status, err := c.refresh(ctx)
+	if err != nil {
+		return Breed{}, false, "", err
+	}
+	for _, b := range list {
+		if b.ID == id {
+			return b, true, status, nil
+		}
+	}
+	return Breed{}, false, status, nil
+}
+
+// refresh makes sure the cached list is usable and returns it. The list is
+// replaced rather than modified, so it may be read without holding c.mu.
+// c.mu is not held while the upstream is asked; concurrent refreshes share
+// one request through the client's coalescing. A caller giving up is not
+// an upstream failure and leaves the cache as it was.
+func (c *BreedCache) refresh(ctx context.Context) ([]Breed, CacheStatus, error) {
+	c.mu.Lock()
+	now := c.now()
+	if c.loaded && now.Before(c.expiresAt) {
+		defer c.mu.Unlock()
+		if c.stale {
+			c.staleServed++
+			return c.breeds, CacheStale, nil
+		}
+		c.hits++
+		return c.breeds, CacheHit, nil
+	}
+	sentETag := c.etag
+	c.mu.Unlock()
+
+	breeds, etag, notModified, err := c.client.listBreedsIfNoneMatch(ctx, sentETag)
+	if err != nil && ctx.Err() != nil {
+		return nil, "", ctx.Err()
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err != nil {
+		if !c.loaded {
+			if c.snapshot == nil {
+				c.errors++
+				return nil, "", err
+			}
+			c.breeds, c.loaded, c.fromSnapshot = c.snapshot, true, true
+			c.fetchedAt = c.snapshotAt
+		}
+		// Serve what we have and give the upstream a moment before retrying
+		c.stale = true
+		c.expiresAt = now.Add(staleRetryInterval)
+		c.staleServed++
+		return c.breeds, CacheStale, nil
+	}
+
+	c.stale = false
+	c.fetchedAt = now
+	c.expiresAt = now.Add(c.ttl)
+	if notModified {
+		c.revalidated++
+		return c.breeds, CacheRevalidated, nil
+	}
+
+	c.breeds, c.etag, c.loaded, c.fromSnapshot = breeds, etag, true, false
+	c.misses++
+	return c.breeds, CacheMiss, nil
+}
+
+// Stats returns the cache counters.
+func (c *BreedCache) Stats() CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return CacheStats{
+		Hits:        c.hits,
+		Misses:      c.misses,
+		Revalidated: c.revalidated,
+		Stale:       c.staleServed,
+		Errors:      c.errors,
+		Breeds:      len(c.breeds),
+		Snapshot:    c.fromSnapshot,
+		FetchedAt:   c.fetchedAt,
+		ExpiresAt:   c.expiresAt,
+	}
+}
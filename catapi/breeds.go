@@ -0,0 +1,231 @@
+package catapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Breed is a cat breed as returned by /breeds.
+type Breed struct {
+	ID           string `json:"id"`
+	Name         string `json:"name"`
+	AltNames     string `json:"alt_names,omitempty"`
+	Description  string `json:"description"`
+	Temperament  string `json:"temperament"`
+	Origin       string `json:"origin"`
+	CountryCode  string `json:"country_code,omitempty"`
+	CountryCodes string `json:"country_codes,omitempty"`
+	LifeSpan     string `json:"life_span"`
+	Weight       Weight `json:"weight"`
+
+	BreedRatings
+	BreedFlags
+
+	WikipediaURL     string `json:"wikipedia_url"`
+	CFAURL           string `json:"cfa_url,omitempty"`
+	VetstreetURL     string `json:"vetstreet_url,omitempty"`
+	VCAHospitalsURL  string `json:"vcahospitals_url,omitempty"`
+	ReferenceImageID string `json:"reference_image_id,omitempty"`
+	Image            *Image `json:"image,omitempty"`
+}
+
+// Weight is a breed's typical weight range, as display strings such as
+// "3 - 5".
+type Weight struct {
+	Imperial string `json:"imperial"`
+	Metric   string `json:"metric"`
+}
+
+// BreedRatings are a breed's trait levels from 1 (least) to 5 (most). Zero
+// means the upstream did not rate the trait.
+type BreedRatings struct {
+	Adaptability     int `json:"adaptability"`
+	AffectionLevel   int `json:"affection_level"`
+	ChildFriendly    int `json:"child_friendly"`
+	CatFriendly      int `json:"cat_friendly,omitempty"`
+	DogFriendly      int `json:"dog_friendly"`
+	EnergyLevel      int `json:"energy_level"`
+	Grooming         int `json:"grooming"`
+	HealthIssues     int `json:"health_issues"`
+	Intelligence     int `json:"intelligence"`
+	SheddingLevel    int `json:"shedding_level"`
+	SocialNeeds      int `json:"social_needs"`
+	StrangerFriendly int `json:"stranger_friendly"`
+	Vocalisation     int `json:"vocalisation"`
+	Bidability       int `json:"bidability,omitempty"`
+}
+
+// BreedFlags are the yes/no attributes of a breed.
+type BreedFlags struct {
+	Indoor         Flag `json:"indoor"`
+	Lap            Flag `json:"lap"`
+	Hypoallergenic Flag `json:"hypoallergenic"`
+	Experimental   Flag `json:"experimental"`
+	Hairless       Flag `json:"hairless"`
+	Natural        Flag `json:"natural"`
+	Rare           Flag `json:"rare"`
+	Rex            Flag `json:"rex"`
+	SuppressedTail Flag `json:"suppressed_tail"`
+	ShortLegs      Flag `json:"short_legs"`
+}
+
+// Flag is a boolean the upstream encodes as 0 or 1. It is written back out
+// as a JSON boolean.
+type Flag bool
+
+// UnmarshalJSON accepts 0, 1, true, false and null.
+func (f *Flag) UnmarshalJSON(data []byte) error {
+	switch string(data) {
+	case "1", "true":
+		*f = true
+	case "0", "false", "null":
+		*f = false
+	default:
+		return fmt.Errorf("catapi: invalid flag %s", data)
+	}
+	return nil
+}
+
+// ListBreeds returns every breed known to the API.
+func (c *Client) ListBreeds(ctx context.Context) ([]Breed, error) {
+	req, err := c.newRequest(ctx, http.MethodGet, "/breeds", nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var breeds []Breed
+	if _, err := c.do(req, &breeds); err != nil {
+		return nil, err
+	}
+	return breeds, nil
+}
+
+// listBreedsIfNoneMatch fetches /breeds unless etag still matches, in which
+// case notModified is true. It returns the ETag of the fresh list.
+func (c *Client) listBreedsIfNoneMatch(ctx context.Context, etag string) (breeds []Breed, newETag string, notModified bool, err error) {
+	req, err := c.newRequest(ctx, http.MethodGet, "/breeds", nil, nil)
+	if err != nil {
+		return nil, "", false, err
+	}
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+
+	header, err := c.do(req, &breeds)
+	if errors.Is(err, errNotModified) {
+		return nil, etag, true, nil
+	}
+	if err != nil {
+		return nil, "", false, err
+	}
+	return breeds, header.Get("ETag"), false, nil
+}
+
+// RatingNames lists the JSON names of the BreedRatings traits.
+var RatingNames = []string{
+	"adaptability", "affection_level", "child_friendly", "cat_friendly",
+	"dog_friendly", "energy_level", "grooming", "health_issues",
+	"intelligence", "shedding_level", "social_needs", "stranger_friendly",
+	"vocalisation", "bidability",
+}
+
+// Rating returns the trait with the given JSON name. ok is false for
+// names not in RatingNames.
+func (r BreedRatings) Rating(name string) (level int, ok bool) {
+	switch name {
+	case "adaptability":
+		return r.Adaptability, true
+	case "affection_level":
+		return r.AffectionLevel, true
+	case "child_friendly":
+		return r.ChildFriendly, true
+	case "cat_friendly":
+		return r.CatFriendly, true
+	case "dog_friendly":
+		return r.DogFriendly, true
+	case "energy_level":
+		return r.EnergyLevel, true
+	case "grooming":
+		return r.Grooming, true
+	case "health_issues":
+		return r.HealthIssues, true
+	case "intelligence":
+		return r.Intelligence, true
+	case "shedding_level":
+		return r.SheddingLevel, true
+	case "social_needs":
+		return r.SocialNeeds, true
+	case "stranger_friendly":
+		return r.StrangerFriendly, true
+	case "vocalisation":
+		return r.Vocalisation, true
+	case "bidability":
+		return r.Bidability, true
+	}
+	return 0, false
+}
+
+// FlagNames lists the JSON names of the BreedFlags attributes.
+var FlagNames = []string{
+	"indoor", "lap", "hypoallergenic", "experimental", "hairless",
+	"natural", "rare", "rex", "suppressed_tail", "short_legs",
+}
+
+// Flag returns the attribute with the given JSON name. ok is false for
+// names not in FlagNames.
+func (f BreedFlags) Flag(name string) (set, ok bool) {
+	switch name {
+	case "indoor":
+		return bool(f.Indoor), true
+	case "lap":
+		return bool(f.Lap), true
+	case "hypoallergenic":
+		return bool(f.Hypoallergenic), true
+	case "experimental":
+		return bool(f.Experimental), true
+	case "hairless":
+		return bool(f.Hairless), true
+	case "natural":
+		return bool(f.Natural), true
+	case "rare":
+		return bool(f.Rare), true
+	case "rex":
+		return bool(f.Rex), true
+	case "suppressed_tail":
+		return bool(f.SuppressedTail), true
+	case "short_legs":
+		return bool(f.ShortLegs), true
+	}
+	return false, false
+}
+
+// LifeSpanYears parses LifeSpan, e.g. "12 - 15", into a range in years.
+func (b Breed) LifeSpanYears() (low, high float64, ok bool) {
+	return parseRange(b.LifeSpan)
+}
+
+// MetricKilograms parses the metric weight into a range in kilograms.
+func (w Weight) MetricKilograms() (low, high float64, ok bool) {
+	return parseRange(w.Metric)
+}
+
+// parseRange reads "a - b" or a single number.
+func parseRange(s string) (low, high float64, ok bool) {
+	lo, hi, found := strings.Cut(s, "-")
+	low, err := strconv.ParseFloat(strings.TrimSpace(lo), 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	if !found {
+		return low, low, true
+	}
+	high, err = strconv.ParseFloat(strings.TrimSpace(hi), 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	return low, high, true
+}
@@ -0,0 +1,288 @@
+// Package catapi is a small typed client for The Cat API
+// (https://thecatapi.com). Controllers use it instead of building
+// their own HTTP requests so that upstream behaviour lives in one place.
+package catapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultBaseURL is the public Cat API endpoint.
+const DefaultBaseURL = "https://api.thecatapi.com/v1"
+
+// Client talks to The Cat API. It is safe for concurrent use.
+type Client struct {
+	baseURL    string
+	apiKey     string
+	httpClient *http.Client
+	flights    coalescer
+	retry      RetryPolicy
+	breaker    breaker
+	retries    atomic.Int64
+}
+
+// Option configures a Client.
+type Option func(*Client)
+
+// WithBaseURL overrides the API base URL, e.g. to point at a local fake.
+func WithBaseURL(baseURL string) Option {
+	return func(c *Client) {
+		if baseURL != "" {
+			c.baseURL = strings.TrimRight(baseURL, "/")
+		}
+	}
+}
+
+// WithHTTPClient sets the http.Client used for outbound requests.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) {
+		if hc != nil {
+			c.httpClient = hc
+		}
+	}
+}
+
+// New returns a Client authenticating with apiKey.
+func New(apiKey string, opts ...Option) *Client {
+	c := &Client{
+		baseURL:    DefaultBaseURL,
+		apiKey:     apiKey,
+		httpClient: &http.Client{},
+		retry:      DefaultRetryPolicy,
+		breaker: breaker{
+			threshold: DefaultBreakerThreshold,
+			cooldown:  DefaultBreakerCooldown,
+			now:       time.Now,
+			state:     BreakerClosed,
+		},
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// BaseURL returns the base URL the client sends requests to.
+func (c *Client) BaseURL() string {
+	return c.baseURL
+}
+
+// APIError is returned when the upstream answers with a non-2xx status.
+type APIError struct {
+	Method     string
+	Path       string
+	StatusCode int
+	Body       string
+}
+
+func (e *APIError) Error() string {
+	msg := strings.TrimSpace(e.Body)
+	if msg == "" {
+		msg = http.StatusText(e.StatusCode)
+	}
+	return fmt.Sprintf("catapi: %s %s: %d %s", e.Method, e.Path, e.StatusCode, msg)
+}
+
+// Pagination is the paging metadata the upstream reports in the
+// Pagination-* headers of list endpoints.
+type Pagination struct {
+	Count int `json:"count"`
+	Page  int `json:"page"`
+	Limit int `json:"limit"`
+}
+
+func parsePagination(h http.Header) Pagination {
+	atoi := func(name string) int {
+		n, _ := strconv.Atoi(h.Get(name))
+		return n
+	}
+	return Pagination{
+		Count: atoi("Pagination-Count"),
+		Page:  atoi("Pagination-Page"),
+		Limit: atoi("Pagination-Limit"),
+	}
+}
+
+// errNotModified is returned by do for a 304 answer to a conditional request.
+var errNotModified = errors.New("catapi: not modified")
+
+// IsNotFound reports whether err is an APIError with status 404.
+func IsNotFound(err error) bool {
+	return hasStatus(err, http.StatusNotFound)
+}
+
+func hasStatus(err error, status int) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == status
+}
+
+// newRequest builds an authenticated request for path (relative to the
+// base URL) with the given query and optional JSON body.
+func (c *Client) newRequest(ctx context.Context, method, path string, query url.Values, body interface{}) (*http.Request, error) {
+	u := c.baseURL + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("catapi: encode %s %s: %w", method, path, err)
+		}
+		reader = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u, reader)
+	if err != nil {
+		return nil, fmt.Errorf("catapi: build %s %s: %w", method, path, err)
+	}
+	req.Header.Set("x-api-key", c.apiKey)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	return req, nil
+}
+
+// do sends req and decodes a successful JSON response into out (if non-nil).
+// It returns the response headers so callers can read paging metadata.
+func (c *Client) do(req *http.Request, out interface{}) (http.Header, error) {
+	resp, err := c.send(req)
+	if err != nil {
+		return nil, fmt.Errorf("catapi: %s %s: %w", req.Method, req.URL.Path, err)
+	}
+
+	if resp.status == http.StatusNotModified {
+		return resp.header, errNotModified
+	}
+	if resp.status < 200 || resp.status > 299 {
+		body := resp.body
+		if len(body) > 4096 {
+			body = body[:4096]
+		}
+		return resp.header, &APIError{
+			Method:     req.Method,
+			Path:       req.URL.Path,
+			StatusCode: resp.status,
+			Body:       string(body),
+		}
+	}
+
+	if out == nil {
+		return resp.header, nil
+	}
+	if err := json.Unmarshal(resp.body, out); err != nil {
+		return resp.header, fmt.Errorf("catapi: decode %s %s: %w", req.Method, req.URL.Path, err)
+	}
+	return resp.header, nil
+}
+
+// noCoalesceKey marks a context whose requests must not be coalesced.
+type noCoalesceKey struct{}
+
+// WithoutCoalescing returns a context whose GET requests each get their own
+// upstream round trip, for endpoints such as random image searches where
+// identical requests must not share an answer.
+func WithoutCoalescing(ctx context.Context) context.Context {
+	return context.WithValue(ctx, noCoalesceKey{}, true)
+}
+
+// send performs req. Identical concurrent GETs (same URL, key and
+// validators) share a single upstream round trip unless the request
+// context comes from WithoutCoalescing.
+func (c *Client) send(req *http.Request) (*response, error) {
+	if req.Method != http.MethodGet || req.Context().Value(noCoalesceKey{}) != nil {
+		return c.roundTrip(req)
+	}
+
+	key := strings.Join([]string{
+		req.Method,
+		req.URL.String(),
+		req.Header.Get("x-api-key"),
+		req.Header.Get("If-None-Match"),
+	}, " ")
+	return c.flights.do(req.Context(), key, func(ctx context.Context) (*response, error) {
+		return c.roundTrip(req.WithContext(ctx))
+	})
+}
+
+// roundTrip sends req through the circuit breaker, retrying idempotent
+// requests that fail transiently.
+func (c *Client) roundTrip(req *http.Request) (*response, error) {
+	attempts := 1
+	if idempotent(req.Method) {
+		attempts = c.retry.MaxAttempts
+	}
+
+	for attempt := 0; ; attempt++ {
+		if err := c.breaker.allow(); err != nil {
+			return nil, err
+		}
+		resp, err := c.attempt(req)
+		c.breaker.record(resp, err)
+
+		if attempt+1 >= attempts || !transient(resp, err) {
+			return resp, err
+		}
+		wait, ok := c.retry.delay(attempt, resp, c.breaker.now())
+		if !ok {
+			return resp, err
+		}
+
+		c.retries.Add(1)
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		}
+		if req.GetBody != nil {
+			if req.Body, err = req.GetBody(); err != nil {
+				return nil, err
+			}
+		}
+	}
+}
+
+// attempt sends req once and reads the whole body.
+func (c *Client) attempt(req *http.Request) (*response, error) {
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	return &response{status: resp.StatusCode, header: resp.Header, body: body}, nil
+}
+
+// ClientStats are the counters of a Client.
+type ClientStats struct {
+	Coalesced int64        `json:"coalesced"`
+	Retries   int64        `json:"retries"`
+	Breaker   BreakerStats `json:"breaker"`
+}
+
+// Stats returns the client's counters and circuit breaker state.
+func (c *Client) Stats() ClientStats {
+	return ClientStats{
+		Coalesced: c.flights.count(),
+		Retries:   c.retries.Load(),
+		Breaker:   c.breaker.stats(),
+	}
+}
@@ -0,0 +1,89 @@
+package catapi
+
+import (
+	"context"
+	"net/http"
+	"sync"
+)
+
+// response is a fully read upstream answer that can be handed to every
+// caller sharing a request.
+type response struct {
+	status int
+	header http.Header
+	body   []byte
+}
+
+// flight is an upstream request in progress, shared by identical callers.
+type flight struct {
+	done    chan struct{}
+	resp    *response
+	err     error
+	waiters int
+	cancel  context.CancelFunc
+}
+
+// coalescer collapses identical concurrent requests into one. The shared
+// request outlives any single caller and is only cancelled once every
+// caller has given up.
+type coalescer struct {
+	mu        sync.Mutex
+	flights   map[string]*flight
+	coalesced int64
+}
+
+func (g *coalescer) do(ctx context.Context, key string, fn func(context.Context) (*response, error)) (*response, error) {
+	g.mu.Lock()
+	if g.flights == nil {
+		g.flights = map[string]*flight{}
+	}
+	f, ok := g.flights[key]
+	if ok {
+		f.waiters++
+		g.coalesced++
+	} else {
+		fctx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+		f = &flight{done: make(chan struct{}), waiters: 1, cancel: cancel}
+		g.flights[key] = f
+		go func() {
+			f.resp, f.err = fn(fctx)
+			cancel()
+			g.forget(key, f)
+			close(f.done)
+		}()
+	}
+	g.mu.Unlock()
+
+	select {
+	case <-f.done:
+		return f.resp, f.err
+	case <-ctx.Done():
+		g.mu.Lock()
+		f.waiters--
+		if f.waiters == 0 {
+			f.cancel()
+			g.forgetLocked(key, f)
+		}
+		g.mu.Unlock()
+		return nil, ctx.Err()
+	}
+}
+
+func (g *coalescer) forget(key string, f *flight) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.forgetLocked(key, f)
+}
+
+// forgetLocked stops new callers joining f. Callers must hold g.mu.
+func (g *coalescer) forgetLocked(key string, f *flight) {
+	if g.flights[key] == f {
+		delete(g.flights, key)
+	}
+}
+
+func (g *coalescer) count() int64 {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.coalesced
+}
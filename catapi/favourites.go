@@ -0,0 +1,108 @@
+package catapi
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// Favourite is a saved image as returned by /favourites.
+type Favourite struct {
+	ID        int    `json:"id"`
+	ImageID   string `json:"image_id"`
+	SubID     string `json:"sub_id"`
+	CreatedAt string `json:"created_at"`
+	Image     Image  `json:"image"`
+}
+
+// CreateResult is the upstream acknowledgement for created resources.
+type CreateResult struct {
+	ID      int    `json:"id"`
+	Message string `json:"message"`
+}
+
+// FavouriteQuery holds the optional filters and paging for
+// ListFavourites. Page is zero-based; Order is ASC or DESC.
+type FavouriteQuery struct {
+	SubID string
+	Page  int
+	Limit int
+	Order string
+}
+
+func (q FavouriteQuery) values() url.Values {
+	v := url.Values{}
+	if q.SubID != "" {
+		v.Set("sub_id", q.SubID)
+	}
+	if q.Page > 0 {
+		v.Set("page", strconv.Itoa(q.Page))
+	}
+	if q.Limit > 0 {
+		v.Set("limit", strconv.Itoa(q.Limit))
+	}
+	if q.Order != "" {
+		v.Set("order", q.Order)
+	}
+	return v
+}
+
+// ListFavourites returns the account's favourites matching query along
+// with the upstream paging metadata.
+func (c *Client) ListFavourites(ctx context.Context, query FavouriteQuery) ([]Favourite, Pagination, error) {
+	req, err := c.newRequest(ctx, http.MethodGet, "/favourites", query.values(), nil)
+	if err != nil {
+		return nil, Pagination{}, err
+	}
+
+	var favourites []Favourite
+	header, err := c.do(req, &favourites)
+	if err != nil {
+		return nil, Pagination{}, err
+	}
+	return favourites, parsePagination(header), nil
+}
+
+// CreateFavourite saves imageID as a favourite for subID.
+func (c *Client) CreateFavourite(ctx context.Context, imageID, subID string) (*CreateResult, error) {
+	body := map[string]string{
+		"image_id": imageID,
+		"sub_id":   subID,
+	}
+	req, err := c.newRequest(ctx, http.MethodPost, "/favourites", nil, body)
+	if err != nil {
+		return nil, err
+	}
+
+	var result CreateResult
+	if _, err := c.do(req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetFavourite returns the favourite with id.
+func (c *Client) GetFavourite(ctx context.Context, id int) (*Favourite, error) {
+	req, err := c.newRequest(ctx, http.MethodGet, "/favourites/"+strconv.Itoa(id), nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var favourite Favourite
+	if _, err := c.do(req, &favourite); err != nil {
+		return nil, err
+	}
+	return &favourite, nil
+}
+
+// DeleteFavourite removes the favourite with id.
+func (c *Client) DeleteFavourite(ctx context.Context, id int) error {
+	req, err := c.newRequest(ctx, http.MethodDelete, "/favourites/"+strconv.Itoa(id), nil, nil)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.do(req, nil)
+	return err
+}
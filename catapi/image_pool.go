@@ -0,0 +1,216 @@
+package catapi
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// errEmptyBatch is recorded when a refill returns no images.
+var errEmptyBatch = errors.New("no images returned")
+
+// PoolStats are the counters of an ImagePool. Hits are images served from
+// the pool, Misses calls to Take that found it empty.
+type PoolStats struct {
+	Depth        int       `json:"depth"`
+	Capacity     int       `json:"capacity"`
+	Hits         int64     `json:"hits"`
+	Misses       int64     `json:"misses"`
+	Refills      int64     `json:"refills"`
+	RefillErrors int64     `json:"refill_errors"`
+	LastRefillMs float64   `json:"last_refill_ms"`
+	AvgRefillMs  float64   `json:"avg_refill_ms"`
+	MaxRefillMs  float64   `json:"max_refill_ms"`
+	RefilledAt   time.Time `json:"refilled_at"`
+	LastError    string    `json:"last_error,omitempty"`
+}
+
+// ImagePool keeps up to a fixed number of random images ready so callers
+// don't wait on the upstream. A background goroutine tops it up in batches
+// of random searches whenever it drops to half full. Images are handed out
+// once each, and an image already waiting in the pool is not queued again.
+// It is safe for concurrent use.
+type ImagePool struct {
+	client *Client
+	size   int
+	batch  int
+
+	wake   chan struct{}
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	mu           sync.Mutex
+	images       []Image
+	queued       map[string]bool
+	hits         int64
+	misses       int64
+	refills      int64
+	refillErrors int64
+	refillTotal  time.Duration
+	lastRefill   time.Duration
+	maxRefill    time.Duration
+	refilledAt   time.Time
+	lastErr      error
+}
+
+// NewImagePool returns a pool of up to size random images from client,
+// fetched batch at a time, and starts filling it. A size of 0 disables the
+// pool: Take always misses and nothing is fetched. Close stops the refills.
+func NewImagePool(client *Client, size, batch int) *ImagePool {
+	ctx, cancel := context.WithCancel(context.Background())
+	p := &ImagePool{
+		client: client,
+		size:   max(size, 0),
+		batch:  max(batch, 1),
+		wake:   make(chan struct{}, 1),
+		ctx:    ctx,
+		cancel: cancel,
+		done:   make(chan struct{}),
+		queued: map[string]bool{},
+	}
+	if p.size == 0 {
+		close(p.done)
+		return p
+	}
+	go p.run()
+	p.kick()
+	return p
+}
+
+// Take returns the next pooled image, or false if the pool is empty and
+// the caller has to fetch one itself.
+func (p *ImagePool) Take() (Image, bool) {
+	p.mu.Lock()
+	if len(p.images) == 0 {
+		p.misses++
+		p.mu.Unlock()
+		p.kick()
+		return Image{}, false
+	}
+	image := p.images[0]
+	p.images = p.images[1:]
+	delete(p.queued, image.ID)
+	p.hits++
+	low := len(p.images) <= p.size/2
+	p.mu.Unlock()
+
+	if low {
+		p.kick()
+	}
+	return image, true
+}
+
+// Close stops the background refills and waits for one in flight.
+func (p *ImagePool) Close() {
+	p.cancel()
+	<-p.done
+}
+
+// Stats returns the pool's depth, counters and refill latencies.
+func (p *ImagePool) Stats() PoolStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	stats := PoolStats{
+		Depth:        len(p.images),
+		Capacity:     p.size,
+		Hits:         p.hits,
+		Misses:       p.misses,
+		Refills:      p.refills,
+		RefillErrors: p.refillErrors,
+		LastRefillMs: milliseconds(p.lastRefill),
+		MaxRefillMs:  milliseconds(p.maxRefill),
+		RefilledAt:   p.refilledAt,
+	}
+	if attempts := p.refills + p.refillErrors; attempts > 0 {
+		stats.AvgRefillMs = milliseconds(p.refillTotal) / float64(attempts)
+	}
+	if p.lastErr != nil {
+		stats.LastError = p.lastErr.Error()
+	}
+	return stats
+}
+
+// kick asks the refill goroutine to top the pool up, unless already asked.
+func (p *ImagePool) kick() {
+	if p.size == 0 {
+		return
+	}
+	select {
+	case p.wake <- struct{}{}:
+	default:
+	}
+}
+
+// run refills the pool each time it is kicked until it is full, a batch
+// fails or a batch brings nothing new.
+func (p *ImagePool) run() {
+	defer close(p.done)
+	for {
+		select {
+		case <-p.ctx.Done():
+			return
+		case <-p.wake:
+		}
+		for p.depth() < p.size {
+			if p.refill() == 0 {
+				break
+			}
+		}
+	}
+}
+
+func (p *ImagePool) depth() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.images)
+}
+
+// refill fetches one batch and queues the images not already pooled,
+// returning how many were added.
+func (p *ImagePool) refill() int {
+	start := time.Now()
+	images, _, err := p.client.SearchImagesPage(p.ctx, ImageSearch{Limit: p.batch})
+	if err == nil && len(images) == 0 {
+		err = errEmptyBatch
+	}
+	elapsed := time.Since(start)
+	if p.ctx.Err() != nil {
+		return 0 // closed
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.refillTotal += elapsed
+	p.lastRefill = elapsed
+	p.maxRefill = max(p.maxRefill, elapsed)
+	if err != nil {
+		p.refillErrors++
+		p.lastErr = err
+		return 0
+	}
+	p.refills++
+	p.refilledAt = time.Now()
+	p.lastErr = nil
+
+	added := 0
+	for _, image := range images {
+		if len(p.images) == p.size {
+			break
+		}
+		if image.ID == "" || p.queued[image.ID] {
+			continue
+		}
+		p.queued[image.ID] = true
+		p.images = append(p.images, image)
+		added++
+	}
+	return added
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
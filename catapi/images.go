@@ -0,0 +1,88 @@
+package catapi
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Image is a cat picture as returned by /images/search.
+type Image struct {
+	ID     string `json:"id,omitempty"`
+	URL    string `json:"url"`
+	Width  int    `json:"width,omitempty"`
+	Height int    `json:"height,omitempty"`
+}
+
+// ImageSearch holds the optional filters for SearchImages. Zero values
+// are left out of the query so the upstream defaults apply.
+type ImageSearch struct {
+	BreedIDs  []string
+	Limit     int
+	Page      int
+	Order     string
+	MimeTypes []string
+}
+
+func (s ImageSearch) values() url.Values {
+	q := url.Values{}
+	if len(s.BreedIDs) > 0 {
+		q.Set("breed_ids", strings.Join(s.BreedIDs, ","))
+	}
+	if s.Limit > 0 {
+		q.Set("limit", strconv.Itoa(s.Limit))
+	}
+	if s.Page > 0 {
+		q.Set("page", strconv.Itoa(s.Page))
+	}
+	if s.Order != "" {
+		q.Set("order", s.Order)
+	}
+	if len(s.MimeTypes) > 0 {
+		q.Set("mime_types", strings.Join(s.MimeTypes, ","))
+	}
+	return q
+}
+
+// SearchImages returns images matching the given search. Random searches,
+// the upstream default, are never coalesced so concurrent callers each
+// get their own images.
+func (c *Client) SearchImages(ctx context.Context, search ImageSearch) ([]Image, error) {
+	images, _, err := c.SearchImagesPage(ctx, search)
+	return images, err
+}
+
+// SearchImagesPage is SearchImages that also returns the paging metadata.
+// The upstream only reports it for ASC and DESC orders.
+func (c *Client) SearchImagesPage(ctx context.Context, search ImageSearch) ([]Image, Pagination, error) {
+	if order := strings.ToUpper(search.Order); order != "ASC" && order != "DESC" {
+		ctx = WithoutCoalescing(ctx)
+	}
+	req, err := c.newRequest(ctx, http.MethodGet, "/images/search", search.values(), nil)
+	if err != nil {
+		return nil, Pagination{}, err
+	}
+
+	var images []Image
+	header, err := c.do(req, &images)
+	if err != nil {
+		return nil, Pagination{}, err
+	}
+	return images, parsePagination(header), nil
+}
+
+// GetImage returns the image with id.
+func (c *Client) GetImage(ctx context.Context, id string) (*Image, error) {
+	req, err := c.newRequest(ctx, http.MethodGet, "/images/"+url.PathEscape(id), nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var image Image
+	if _, err := c.do(req, &image); err != nil {
+		return nil, err
+	}
+	return &image, nil
+}
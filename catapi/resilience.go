@@ -0,0 +1,229 @@
+package catapi
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned without contacting the upstream while the
+// circuit breaker is open.
+var ErrCircuitOpen = errors.New("catapi: circuit breaker open, upstream unavailable")
+
+// RetryPolicy controls retries of idempotent requests. Delays grow
+// exponentially from BaseDelay up to MaxDelay with full jitter; a
+// Retry-After header overrides the delay unless it exceeds MaxRetryAfter.
+type RetryPolicy struct {
+	MaxAttempts   int
+	BaseDelay     time.Duration
+	MaxDelay      time.Duration
+	MaxRetryAfter time.Duration
+}
+
+// Circuit breaker defaults, used unless WithCircuitBreaker is given.
+const (
+	DefaultBreakerThreshold = 5
+	DefaultBreakerCooldown  = 30 * time.Second
+)
+
+// DefaultRetryPolicy is used unless WithRetry is given.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:   3,
+	BaseDelay:     100 * time.Millisecond,
+	MaxDelay:      2 * time.Second,
+	MaxRetryAfter: 5 * time.Second,
+}
+
+// WithRetry sets the retry policy. MaxAttempts of 1 disables retries.
+func WithRetry(policy RetryPolicy) Option {
+	return func(c *Client) {
+		if policy.MaxAttempts < 1 {
+			policy.MaxAttempts = 1
+		}
+		c.retry = policy
+	}
+}
+
+// WithCircuitBreaker opens the breaker after threshold consecutive upstream
+// failures and lets a trial request through once cooldown has passed.
+func WithCircuitBreaker(threshold int, cooldown time.Duration) Option {
+	return func(c *Client) {
+		c.breaker.threshold = threshold
+		c.breaker.cooldown = cooldown
+	}
+}
+
+// WithClock overrides the clock used by the circuit breaker, for tests.
+func WithClock(now func() time.Time) Option {
+	return func(c *Client) {
+		c.breaker.now = now
+	}
+}
+
+// idempotent reports whether a request with method may safely be repeated.
+func idempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// transient reports whether an attempt failed in a way worth retrying and
+// counting against the breaker.
+func transient(resp *response, err error) bool {
+	if err != nil {
+		return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+	}
+	switch resp.status {
+	case http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// delay returns how long to wait before retry number attempt (from 0).
+// ok is false when the upstream asked for a longer wait than allowed.
+func (p RetryPolicy) delay(attempt int, resp *response, now time.Time) (time.Duration, bool) {
+	if resp != nil {
+		if after, ok := parseRetryAfter(resp.header.Get("Retry-After"), now); ok {
+			return after, after <= p.MaxRetryAfter
+		}
+	}
+
+	backoff := p.BaseDelay << attempt
+	if backoff <= 0 || backoff > p.MaxDelay {
+		backoff = p.MaxDelay
+	}
+	if backoff <= 0 {
+		return 0, true
+	}
+	return rand.N(backoff + 1), true
+}
+
+// parseRetryAfter reads a Retry-After value in seconds or as an HTTP date.
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(value); err == nil && secs >= 0 {
+		return time.Duration(secs) * time.Second, true
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		if d := at.Sub(now); d > 0 {
+			return d, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+// BreakerState is the state of the circuit breaker.
+type BreakerState string
+
+const (
+	BreakerClosed   BreakerState = "closed"
+	BreakerOpen     BreakerState = "open"
+	BreakerHalfOpen BreakerState = "half-open"
+)
+
+// BreakerStats describe the circuit breaker.
+type BreakerStats struct {
+	State               BreakerState `json:"state"`
+	ConsecutiveFailures int          `json:"consecutive_failures"`
+	Opens               int64        `json:"opens"`
+	Rejected            int64        `json:"rejected"`
+	OpenedAt            time.Time    `json:"opened_at"`
+}
+
+// breaker is a consecutive-failure circuit breaker. While open it rejects
+// requests; after cooldown it admits a single trial whose outcome closes
+// or re-opens it. A threshold of zero or less disables it.
+type breaker struct {
+	threshold int
+	cooldown  time.Duration
+	now       func() time.Time
+
+	mu       sync.Mutex
+	state    BreakerState
+	failures int
+	openedAt time.Time
+	trial    bool
+	opens    int64
+	rejected int64
+}
+
+// allow reports whether a request may be sent now.
+func (b *breaker) allow() error {
+	if b.threshold <= 0 {
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case BreakerOpen:
+		if b.now().Sub(b.openedAt) < b.cooldown {
+			b.rejected++
+			return ErrCircuitOpen
+		}
+		b.state = BreakerHalfOpen
+		b.trial = true
+		return nil
+	case BreakerHalfOpen:
+		if b.trial {
+			b.rejected++
+			return ErrCircuitOpen
+		}
+		b.trial = true
+		return nil
+	}
+	return nil
+}
+
+// record feeds the outcome of an allowed request into the breaker.
+// Requests abandoned by the caller count as neither.
+func (b *breaker) record(resp *response, err error) {
+	if b.threshold <= 0 {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.trial = false
+	switch {
+	case transient(resp, err):
+		b.failures++
+		if b.state == BreakerHalfOpen || (b.state == BreakerClosed && b.failures >= b.threshold) {
+			b.state = BreakerOpen
+			b.openedAt = b.now()
+			b.opens++
+		}
+	case err != nil:
+		// cancelled by the caller; says nothing about the upstream
+	default:
+		b.failures = 0
+		b.state = BreakerClosed
+	}
+}
+
+func (b *breaker) stats() BreakerStats {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	s := BreakerStats{
+		State:               b.state,
+		ConsecutiveFailures: b.failures,
+		Opens:               b.opens,
+		Rejected:            b.rejected,
+	}
+	if b.state != BreakerClosed {
+		s.OpenedAt = b.openedAt
+	}
+	return s
+}
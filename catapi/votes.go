@@ -0,0 +1,96 @@
+package catapi
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// NewVote is the payload for CreateVote. Value is 1 for a like and -1
+// for a dislike.
+type NewVote struct {
+	ImageID string `json:"image_id"`
+	SubID   string `json:"sub_id"`
+	Value   int    `json:"value"`
+}
+
+// Vote is a recorded vote as returned by /votes. Image may be empty when
+// the upstream could not attach it.
+type Vote struct {
+	ID          int    `json:"id"`
+	ImageID     string `json:"image_id"`
+	SubID       string `json:"sub_id"`
+	Value       int    `json:"value"`
+	CountryCode string `json:"country_code,omitempty"`
+	CreatedAt   string `json:"created_at"`
+	Image       Image  `json:"image"`
+}
+
+// VoteQuery holds the optional filters and paging for ListVotes. Page is
+// zero-based; Order is ASC or DESC.
+type VoteQuery struct {
+	SubID string
+	Page  int
+	Limit int
+	Order string
+}
+
+func (q VoteQuery) values() url.Values {
+	return FavouriteQuery(q).values()
+}
+
+// ListVotes returns the account's votes matching query along with the
+// upstream paging metadata.
+func (c *Client) ListVotes(ctx context.Context, query VoteQuery) ([]Vote, Pagination, error) {
+	req, err := c.newRequest(ctx, http.MethodGet, "/votes", query.values(), nil)
+	if err != nil {
+		return nil, Pagination{}, err
+	}
+
+	var votes []Vote
+	header, err := c.do(req, &votes)
+	if err != nil {
+		return nil, Pagination{}, err
+	}
+	return votes, parsePagination(header), nil
+}
+
+// CreateVote records a vote on an image.
+func (c *Client) CreateVote(ctx context.Context, vote NewVote) (*CreateResult, error) {
+	req, err := c.newRequest(ctx, http.MethodPost, "/votes", nil, vote)
+	if err != nil {
+		return nil, err
+	}
+
+	var result CreateResult
+	if _, err := c.do(req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetVote returns the vote with id.
+func (c *Client) GetVote(ctx context.Context, id int) (*Vote, error) {
+	req, err := c.newRequest(ctx, http.MethodGet, "/votes/"+strconv.Itoa(id), nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var vote Vote
+	if _, err := c.do(req, &vote); err != nil {
+		return nil, err
+	}
+	return &vote, nil
+}
+
+// DeleteVote removes the vote with id.
+func (c *Client) DeleteVote(ctx context.Context, id int) error {
+	req, err := c.newRequest(ctx, http.MethodDelete, "/votes/"+strconv.Itoa(id), nil, nil)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.do(req, nil)
+	return err
+}
@@ -0,0 +1,80 @@
+// Command breedsnapshot downloads the Cat API breed list, with reference
+// image metadata, into the snapshot the app embeds and serves while the
+// upstream is unreachable. Run it from the repository root and rebuild:
+//
+//	go run ./cmd/breedsnapshot -api-key "$CAT_API_KEY"
+//
+// With -check it downloads nothing and fails unless the snapshot at -out
+// has breeds and is younger than -max-age; release builds run it before
+// go build.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"myproject/catapi"
+	"myproject/snapshot"
+)
+
+func main() {
+	out := flag.String("out", "snapshot/breeds.json", "file to write the snapshot to")
+	baseURL := flag.String("base-url", catapi.DefaultBaseURL, "Cat API base URL")
+	apiKey := flag.String("api-key", os.Getenv("CAT_API_KEY"), "Cat API key (defaults to $CAT_API_KEY)")
+	timeout := flag.Duration("timeout", 2*time.Minute, "give up after this long")
+	check := flag.Bool("check", false, "only verify the snapshot at -out instead of downloading one")
+	maxAge := flag.Duration("max-age", 90*24*time.Hour, "with -check, the oldest acceptable snapshot (0 for any)")
+	flag.Parse()
+
+	if *check {
+		snap, err := snapshot.Load(*out)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := snap.Check(time.Now(), *maxAge); err != nil {
+			log.Fatalf("%s: %v", *out, err)
+		}
+		log.Printf("%s has %d breeds taken at %s", *out, len(snap.Breeds), snap.TakenAt.Format(time.RFC3339))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	client := catapi.New(*apiKey, catapi.WithBaseURL(*baseURL))
+	snap, err := snapshot.Take(ctx, client)
+	if err != nil {
+		log.Fatal(err)
+	}
+	// Never replace a good snapshot with an empty one
+	if err := snap.Check(time.Now(), 0); err != nil {
+		log.Fatal(err)
+	}
+
+	data, err := json.MarshalIndent(snap, "", "  ")
+	if err != nil {
+		log.Fatal(err)
+	}
+	// Write next to the target and rename so a failed run leaves the old
+	// snapshot intact
+	tmp, err := os.CreateTemp(filepath.Dir(*out), ".breeds-*.json")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		log.Fatal(err)
+	}
+	if err := tmp.Close(); err != nil {
+		log.Fatal(err)
+	}
+	if err := os.Rename(tmp.Name(), *out); err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("Wrote %d breeds from %s to %s (version %d)", len(snap.Breeds), snap.Source, *out, snap.Version)
+}
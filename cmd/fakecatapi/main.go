@@ -0,0 +1,26 @@
+// Command fakecatapi serves an offline, in-memory imitation of The Cat API
+// for local development. Point the app at it by setting
+// catapi_base_url = http://localhost:8081/v1 in conf/app.conf.
+package main
+
+import (
+	"flag"
+	"log"
+	"net/http"
+
+	"myproject/fakecatapi"
+)
+
+func main() {
+	addr := flag.String("addr", ":8081", "address to listen on")
+	seed := flag.Int64("seed", 0, "seed for random image selection (0 picks a random seed)")
+	flag.Parse()
+
+	var opts []fakecatapi.Option
+	if *seed != 0 {
+		opts = append(opts, fakecatapi.WithSeed(*seed))
+	}
+
+	log.Println("Fake Cat API listening on", *addr)
+	log.Fatal(http.ListenAndServe(*addr, fakecatapi.New(opts...)))
+}
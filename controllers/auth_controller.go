@@ -0,0 +1,164 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/beego/beego/v2/server/web"
+
+	"myproject/models"
+)
+
+// sessionUserKey is the session key holding the logged-in user's ID.
+const sessionUserKey = "user_id"
+
+var (
+	userStoreOnce sync.Once
+	userStore     *models.UserStore
+	userStoreErr  error
+)
+
+// defaultUserStore opens the user store configured by user_store in
+// app.conf once per process.
+func defaultUserStore() (*models.UserStore, error) {
+	userStoreOnce.Do(func() {
+		path := web.AppConfig.DefaultString("user_store", "data/users.json")
+		userStore, userStoreErr = models.NewUserStore(path)
+	})
+	return userStore, userStoreErr
+}
+
+// sessionUserID returns the logged-in user's ID, or "" when nobody is
+// logged in or sessions are disabled.
+func sessionUserID(c *web.Controller) string {
+	if c.CruSession == nil && c.Ctx.Input.CruSession == nil {
+		return ""
+	}
+	id, _ := c.GetSession(sessionUserKey).(string)
+	return id
+}
+
+// requireUser returns the logged-in user's ID, or answers 401 and returns
+// "" when there is none.
+func requireUser(c *web.Controller) string {
+	userID := sessionUserID(c)
+	if userID == "" {
+		c.Ctx.Output.SetStatus(http.StatusUnauthorized)
+		c.Data["json"] = errorBody(c.Ctx, "Login required")
+		c.ServeJSON()
+	}
+	return userID
+}
+
+type AuthController struct {
+	web.Controller
+	Users *models.UserStore
+}
+
+// users returns the injected store or the process-wide one
+func (c *AuthController) users() (*models.UserStore, error) {
+	if c.Users != nil {
+		return c.Users, nil
+	}
+	return defaultUserStore()
+}
+
+func (c *AuthController) serveError(status int, msg string) {
+	c.Ctx.Output.SetStatus(status)
+	c.Data["json"] = errorBody(c.Ctx, msg)
+	c.ServeJSON()
+}
+
+// logIn starts a fresh session for u and responds with its public fields
+func (c *AuthController) logIn(u *models.User) {
+	if web.GlobalSessions != nil {
+		if err := c.SessionRegenerateID(); err != nil {
+			fmt.Println("Failed to regenerate session:", err)
+		}
+	}
+	if err := c.SetSession(sessionUserKey, u.ID); err != nil {
+		c.serveError(http.StatusInternalServerError, "Failed to start session")
+		return
+	}
+
+	c.Data["json"] = map[string]interface{}{"id": u.ID, "username": u.Username}
+	c.ServeJSON()
+}
+
+// Register creates an account and logs it in
+func (c *AuthController) Register() {
+	users, err := c.users()
+	if err != nil {
+		fmt.Println("Failed to open user store:", err)
+		c.serveError(http.StatusInternalServerError, "User store is unavailable")
+		return
+	}
+
+	u, err := users.Register(c.GetString("username"), c.GetString("password"))
+	switch {
+	case errors.Is(err, models.ErrUserExists):
+		c.serveError(http.StatusConflict, err.Error())
+		return
+	case errors.Is(err, models.ErrInvalidUsername), errors.Is(err, models.ErrWeakPassword):
+		c.serveError(http.StatusBadRequest, err.Error())
+		return
+	case err != nil:
+		fmt.Println("Failed to register user:", err)
+		c.serveError(http.StatusInternalServerError, "Failed to register user")
+		return
+	}
+
+	c.Ctx.Output.SetStatus(http.StatusCreated)
+	c.logIn(u)
+}
+
+// Login checks the credentials and stores the user in the session
+func (c *AuthController) Login() {
+	users, err := c.users()
+	if err != nil {
+		fmt.Println("Failed to open user store:", err)
+		c.serveError(http.StatusInternalServerError, "User store is unavailable")
+		return
+	}
+
+	u, err := users.Authenticate(c.GetString("username"), c.GetString("password"))
+	if err != nil {
+		c.serveError(http.StatusUnauthorized, err.Error())
+		return
+	}
+	c.logIn(u)
+}
+
+// Logout clears the session
+func (c *AuthController) Logout() {
+	if sessionUserID(&c.Controller) != "" {
+		if err := c.DelSession(sessionUserKey); err != nil {
+			fmt.Println("Failed to clear session:", err)
+		}
+	}
+	c.Data["json"] = map[string]interface{}{"status": "logged out"}
+	c.ServeJSON()
+}
+
+// Me returns the logged-in user
+func (c *AuthController) Me() {
+	userID := requireUser(&c.Controller)
+	if userID == "" {
+		return
+	}
+
+	users, err := c.users()
+	if err != nil {
+		c.serveError(http.StatusInternalServerError, "User store is unavailable")
+		return
+	}
+	u, ok := users.Get(userID)
+	if !ok {
+		c.serveError(http.StatusUnauthorized, "Login required")
+		return
+	}
+	c.Data["json"] = map[string]interface{}{"id": u.ID, "username": u.Username}
+	c.ServeJSON()
+}
@@ -0,0 +1,170 @@
+package controllers
+
+import (
+	"encoding/gob"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"myproject/catapi"
+)
+
+// Breed gallery paging limits. The default matches the eight images the
+// breed tab always showed.
+const (
+	defaultGalleryLimit = 8
+	maxGalleryLimit     = 25
+	// maxGallerySeen caps the image IDs remembered for dedup
+	maxGallerySeen = 1000
+)
+
+// gallerySessionKey holds the galleryState of the session's latest gallery
+const gallerySessionKey = "gallery"
+
+// galleryState is the image IDs already served for one gallery, named by
+// its breed, order and formats. A session remembers a single gallery, so
+// opening another one forgets the previous.
+type galleryState struct {
+	Key  string
+	Seen []string
+}
+
+func init() {
+	// Session providers other than memory store values with gob
+	gob.Register(galleryState{})
+}
+
+// galleryMimeTypes are the image formats the upstream can filter on
+var galleryMimeTypes = map[string]bool{"jpg": true, "png": true, "gif": true}
+
+// GalleryPage is the paging metadata of a breed gallery response. Count
+// is only known for ASC and DESC orders.
+type GalleryPage struct {
+	Page       int    `json:"page"`
+	Limit      int    `json:"limit"`
+	Order      string `json:"order"`
+	Count      int    `json:"count,omitempty"`
+	HasMore    bool   `json:"has_more"`
+	Duplicates int    `json:"duplicates_removed"`
+}
+
+// galleryQuery reads page, limit, order and mime_types for breedID's
+// images. It returns a status and message when they are invalid.
+func (c *BreedSearchController) galleryQuery(breedID string) (catapi.ImageSearch, int, string) {
+	search := catapi.ImageSearch{BreedIDs: []string{breedID}}
+
+	page, err := c.GetInt("page", 0)
+	if err != nil || page < 0 {
+		return search, http.StatusBadRequest, "page must be a non-negative integer"
+	}
+	limit, err := c.GetInt("limit", defaultGalleryLimit)
+	if err != nil || limit < 1 || limit > maxGalleryLimit {
+		return search, http.StatusBadRequest, fmt.Sprintf("limit must be between 1 and %d", maxGalleryLimit)
+	}
+	order := strings.ToUpper(c.GetString("order", "RAND"))
+	if order != "RAND" && order != "ASC" && order != "DESC" {
+		return search, http.StatusBadRequest, "order must be RAND, ASC or DESC"
+	}
+
+	var mimeTypes []string
+	for _, m := range strings.Split(c.GetString("mime_types"), ",") {
+		m = strings.ToLower(strings.TrimSpace(m))
+		if m == "jpeg" {
+			m = "jpg"
+		}
+		if m == "" {
+			continue
+		}
+		if !galleryMimeTypes[m] {
+			return search, http.StatusBadRequest, "mime_types may only list jpg, png and gif"
+		}
+		mimeTypes = append(mimeTypes, m)
+	}
+
+	search.Page, search.Limit, search.Order, search.MimeTypes = page, limit, order, mimeTypes
+	return search, 0, ""
+}
+
+// galleryPage drops images already served to this session for the same
+// gallery, remembering the rest, and describes the page. The first page,
+// or a page of a different gallery, starts afresh. Without a session only
+// repeats within the page are dropped.
+func (c *BreedSearchController) galleryPage(search catapi.ImageSearch, images []BreedImage, upstream catapi.Pagination) ([]BreedImage, GalleryPage) {
+	key := strings.Join(search.BreedIDs, ",") + "|" + search.Order + "|" + strings.Join(search.MimeTypes, ",")
+	hasSession := c.CruSession != nil || c.Ctx.Input.CruSession != nil
+
+	seen := map[string]bool{}
+	var order []string
+	if hasSession && search.Page > 0 {
+		if state, ok := c.GetSession(gallerySessionKey).(galleryState); ok && state.Key == key {
+			order = append([]string(nil), state.Seen...)
+		}
+		for _, id := range order {
+			seen[id] = true
+		}
+	}
+
+	kept := make([]BreedImage, 0, len(images))
+	for _, img := range images {
+		id := img.ID
+		if id == "" {
+			id = img.URL
+		}
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		order = append(order, id)
+		kept = append(kept, img)
+	}
+
+	if hasSession {
+		if len(order) > maxGallerySeen {
+			order = order[len(order)-maxGallerySeen:]
+		}
+		c.SetSession(gallerySessionKey, galleryState{Key: key, Seen: order})
+	}
+
+	page := GalleryPage{
+		Page:       search.Page,
+		Limit:      search.Limit,
+		Order:      search.Order,
+		Count:      upstream.Count,
+		Duplicates: len(images) - len(kept),
+	}
+	if upstream.Limit > 0 {
+		page.HasMore = (search.Page+1)*search.Limit < upstream.Count
+	} else {
+		// Random pages never run out while the upstream fills them
+		page.HasMore = len(images) == search.Limit
+	}
+	return kept, page
+}
+
+// Images returns a page of a breed's image gallery
+func (c *BreedSearchController) Images() {
+	breedID := c.GetString("breed_id")
+	if breedID == "" {
+		c.serveError(http.StatusBadRequest, "Breed ID is required")
+		return
+	}
+	search, status, msg := c.galleryQuery(breedID)
+	if status != 0 {
+		c.serveError(status, msg)
+		return
+	}
+
+	images, upstream, err := c.client().SearchImagesPage(c.Ctx.Request.Context(), search)
+	if err != nil {
+		fmt.Println("Failed to fetch breed images:", err)
+		c.serveError(upstreamStatus(err), "Failed to fetch breed images")
+		return
+	}
+
+	kept, page := c.galleryPage(search, images, upstream)
+	c.Data["json"] = map[string]interface{}{
+		"images":     kept,
+		"pagination": page,
+	}
+	c.ServeJSON()
+}
@@ -0,0 +1,85 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/beego/beego/v2/server/web"
+
+	"myproject/breeds"
+)
+
+// Recommendation limits for Match
+const (
+	defaultMatchLimit = 5
+	maxMatchLimit     = 20
+)
+
+// matcherWeights reads matcher_weight_<question> from app.conf for every
+// question, falling back to breeds.DefaultWeights.
+func matcherWeights() breeds.Weights {
+	weights := breeds.Weights{}
+	for question, def := range breeds.DefaultWeights {
+		weights[question] = web.AppConfig.DefaultFloat("matcher_weight_"+question, def)
+	}
+	return weights
+}
+
+// matcherAnswers reads the questionnaire from the request. allergies and
+// kids take a boolean; other_pets a comma separated list.
+func (c *BreedSearchController) matcherAnswers() (breeds.Answers, error) {
+	answers := breeds.Answers{
+		ApartmentSize: strings.ToLower(strings.TrimSpace(c.GetString("apartment_size"))),
+		TimeAtHome:    strings.ToLower(strings.TrimSpace(c.GetString("time_at_home"))),
+	}
+	for _, name := range []string{"allergies", "kids"} {
+		if c.GetString(name) == "" {
+			continue
+		}
+		value, err := c.GetBool(name)
+		if err != nil {
+			return answers, fmt.Errorf("%s must be true or false", name)
+		}
+		if name == "allergies" {
+			answers.Allergies = &value
+		} else {
+			answers.Kids = &value
+		}
+	}
+	for _, pet := range strings.Split(c.GetString("other_pets"), ",") {
+		if pet = strings.ToLower(strings.TrimSpace(pet)); pet != "" {
+			answers.OtherPets = append(answers.OtherPets, pet)
+		}
+	}
+	return answers, answers.Validate()
+}
+
+// Match recommends breeds for the lifestyle described by the request,
+// with the trait ratings behind each score
+func (c *BreedSearchController) Match() {
+	answers, err := c.matcherAnswers()
+	if err != nil {
+		c.serveError(http.StatusBadRequest, err.Error())
+		return
+	}
+	limit, err := c.GetInt("limit", defaultMatchLimit)
+	if err != nil || limit < 1 || limit > maxMatchLimit {
+		c.serveError(http.StatusBadRequest, fmt.Sprintf("limit must be between 1 and %d", maxMatchLimit))
+		return
+	}
+
+	list, status, err := c.breeds().Breeds(c.Ctx.Request.Context())
+	if err != nil {
+		fmt.Println("Failed to fetch breed list:", err)
+		c.serveError(upstreamStatus(err), "Failed to fetch breed list")
+		return
+	}
+	c.markCache(status)
+
+	c.Data["json"] = map[string]interface{}{
+		"answers": answers,
+		"matches": breeds.Recommend(list, answers, matcherWeights(), limit),
+	}
+	c.ServeJSON()
+}
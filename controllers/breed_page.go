@@ -0,0 +1,183 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/beego/beego/v2/core/config"
+	"github.com/beego/beego/v2/server/web"
+
+	"myproject/catapi"
+	"myproject/i18n"
+)
+
+// breedPageImages is the size of the gallery on a breed page
+const breedPageImages = 12
+
+// ogDescriptionLen caps the Open Graph description, which previews cut
+// short anyway
+const ogDescriptionLen = 200
+
+// BreedPageController renders a shareable HTML page for one breed
+type BreedPageController struct {
+	web.Controller
+	APIKey string
+	API    *catapi.Client
+}
+
+// BreedPageItem is a labelled value shown on a breed page
+type BreedPageItem struct {
+	Label   string
+	Value   string
+	Level   int
+	Percent int
+}
+
+// Ratings and flags shown on breed pages, in display order. Their labels
+// are the catalogue keys breed.rating.<name> and breed.flag.<name>.
+var (
+	breedPageRatings = []string{
+		"affection_level", "energy_level", "intelligence", "child_friendly", "dog_friendly",
+		"cat_friendly", "stranger_friendly", "adaptability", "social_needs", "grooming",
+		"shedding_level", "vocalisation", "health_issues",
+	}
+	breedPageFlags = []string{"hypoallergenic", "indoor", "lap", "hairless", "rare", "natural", "short_legs"}
+)
+
+func (c *BreedPageController) Prepare() {
+	if c.APIKey != "" {
+		return
+	}
+	apiKey, err := config.String("api_key")
+	if err != nil {
+		c.CustomAbort(http.StatusInternalServerError, "Failed to load API key from configuration")
+		return
+	}
+	c.APIKey = apiKey
+}
+
+// client returns the injected Cat API client or the shared one for c.APIKey
+func (c *BreedPageController) client() *catapi.Client {
+	if c.API == nil {
+		c.API = catAPIClient(c.APIKey)
+	}
+	return c.API
+}
+
+// Get renders breed.tpl for the breed in the :id route parameter
+func (c *BreedPageController) Get() {
+	id := c.Ctx.Input.Param(":id")
+	ctx := c.Ctx.Request.Context()
+	api := c.client()
+	lang := requestLang(c.Ctx)
+
+	cache := breedCache(api)
+	breed, ok, status, err := cache.Breed(ctx, id)
+	if err != nil {
+		fmt.Println("Failed to fetch breed list:", err)
+		c.CustomAbort(upstreamStatus(err), i18n.Error(lang, "Failed to fetch breed details"))
+		return
+	}
+	if !ok {
+		c.CustomAbort(http.StatusNotFound, i18n.Error(lang, "Breed not found"))
+		return
+	}
+
+	// The page still works without photos, so a failed search is not fatal
+	images, _, err := api.SearchImagesPage(ctx, catapi.ImageSearch{
+		BreedIDs: []string{id},
+		Limit:    breedPageImages,
+		Order:    "ASC",
+	})
+	if err != nil {
+		fmt.Println("Failed to fetch breed images:", err)
+		if breed.Image != nil {
+			images, status = []catapi.Image{*breed.Image}, catapi.CacheStale
+		}
+	}
+
+	markBreedCache(c.Ctx.Output, cache, status)
+	if status == catapi.CacheStale {
+		c.Data["Stale"] = true
+		if stats := cache.Stats(); stats.Snapshot {
+			c.Data["SnapshotAt"] = stats.FetchedAt.UTC().Format(time.DateOnly)
+		}
+	}
+
+	breed = localizeBreed(c.Ctx, breed)
+	c.Data["Lang"] = lang
+	c.Data["Breed"] = breed
+	c.Data["Images"] = images
+	c.Data["Facts"] = breedFacts(lang, breed)
+	c.Data["Ratings"] = breedRatings(lang, breed)
+	c.Data["Flags"] = breedFlags(lang, breed)
+
+	// The canonical URL comes from app.conf rather than the Host header,
+	// which any client can set
+	if site := strings.TrimRight(web.AppConfig.DefaultString("site_url", ""), "/"); site != "" {
+		c.Data["PageURL"] = site + "/breeds/" + url.PathEscape(breed.ID)
+	}
+	c.Data["OGDescription"] = truncate(breed.Description, ogDescriptionLen)
+	switch {
+	case breed.Image != nil:
+		c.Data["OGImage"] = breed.Image.URL
+	case len(images) > 0:
+		c.Data["OGImage"] = images[0].URL
+	}
+
+	c.TplName = "breed.tpl"
+}
+
+func breedFacts(lang string, breed catapi.Breed) []BreedPageItem {
+	var facts []BreedPageItem
+	add := func(key, value string) {
+		if value != "" {
+			facts = append(facts, BreedPageItem{Label: i18n.T(lang, key), Value: value})
+		}
+	}
+	add("breed.origin", breed.Origin)
+	add("breed.temperament", breed.Temperament)
+	if breed.LifeSpan != "" {
+		add("breed.life_span", fmt.Sprintf(i18n.T(lang, "breed.life_span_years"), breed.LifeSpan))
+	}
+	if breed.Weight.Metric != "" {
+		add("breed.weight", breed.Weight.Metric+" kg ("+breed.Weight.Imperial+" lb)")
+	}
+	add("breed.also_known_as", breed.AltNames)
+	return facts
+}
+
+func breedRatings(lang string, breed catapi.Breed) []BreedPageItem {
+	var ratings []BreedPageItem
+	for _, name := range breedPageRatings {
+		if level, _ := breed.Rating(name); level > 0 {
+			ratings = append(ratings, BreedPageItem{Label: i18n.T(lang, "breed.rating."+name), Level: level, Percent: level * 20})
+		}
+	}
+	return ratings
+}
+
+func breedFlags(lang string, breed catapi.Breed) []string {
+	var flags []string
+	for _, name := range breedPageFlags {
+		if set, _ := breed.Flag(name); set {
+			flags = append(flags, i18n.T(lang, "breed.flag."+name))
+		}
+	}
+	return flags
+}
+
+// truncate shortens s to at most n bytes at a word boundary
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	cut := strings.LastIndex(s[:n], " ")
+	if cut <= 0 {
+		cut = n
+	}
+	return strings.TrimRight(s[:cut], " ,.;") + "…"
+}
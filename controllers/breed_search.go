@@ -1,30 +1,27 @@
 package controllers
 
 import (
-	"encoding/json"
+	"context"
+	"errors"
 	"fmt"
-	"io/ioutil"
 	"net/http"
+	"strings"
 
 	"github.com/beego/beego/v2/core/config"
 	"github.com/beego/beego/v2/server/web"
+
+	"myproject/breeds"
+	"myproject/catapi"
 )
 
-type BreedImage struct {
-	URL string `json:"url"`
-}
+type BreedImage = catapi.Image
 
-type CatBreed struct {
-	ID           string `json:"id"`
-	Name         string `json:"name"`
-	Description  string `json:"description"`
-	Origin       string `json:"origin"`
-	WikipediaURL string `json:"wikipedia_url"`
-}
+type CatBreed = catapi.Breed
 
 type BreedSearchController struct {
 	web.Controller
 	APIKey string
+	API    *catapi.Client
 }
 
 // Initialize the controller with the API key
@@ -43,116 +40,269 @@ func (c *BreedSearchController) Prepare() {
 	c.APIKey = apiKey
 }
 
-// Fetch all breeds
+// client returns the injected Cat API client or the shared one for c.APIKey
+func (c *BreedSearchController) client() *catapi.Client {
+	if c.API == nil {
+		c.API = catAPIClient(c.APIKey)
+	}
+	return c.API
+}
+
+// breeds returns the shared breed cache for the controller's client
+func (c *BreedSearchController) breeds() *catapi.BreedCache {
+	return breedCache(c.client())
+}
+
+// Fetch all breeds, narrowed and ordered by the query parameters
+// understood by breeds.ParseFilter
 func (c *BreedSearchController) Get() {
-	url := "https://api.thecatapi.com/v1/breeds"
+	filter, err := breeds.ParseFilter(c.Ctx.Request.URL.Query())
+	if err != nil {
+		c.serveError(http.StatusBadRequest, err.Error())
+		return
+	}
 
-	req, err := http.NewRequest("GET", url, nil)
+	list, status, err := c.breeds().Breeds(c.Ctx.Request.Context())
 	if err != nil {
-		c.CustomAbort(500, "Failed to create API request")
+		fmt.Println("Failed to fetch breed list:", err)
+		c.serveError(upstreamStatus(err), "Failed to fetch breed list")
 		return
 	}
-	req.Header.Set("x-api-key", c.APIKey)
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	c.markCache(status)
+	list = filter.Apply(list)
+	for i, breed := range list {
+		list[i] = localizeBreed(c.Ctx, breed)
+	}
+	c.Data["json"] = list
+	c.ServeJSON()
+}
+
+// Suggestion limits for Suggest
+const (
+	defaultSuggestLimit = 10
+	maxSuggestLimit     = 25
+)
+
+// Suggest returns breeds matching q, tolerating typos, for autocomplete
+func (c *BreedSearchController) Suggest() {
+	q := strings.TrimSpace(c.GetString("q"))
+	if q == "" {
+		c.serveError(http.StatusBadRequest, "q is required")
+		return
+	}
+	limit, err := c.GetInt("limit", defaultSuggestLimit)
+	if err != nil || limit < 1 || limit > maxSuggestLimit {
+		c.serveError(http.StatusBadRequest, fmt.Sprintf("limit must be between 1 and %d", maxSuggestLimit))
+		return
+	}
+
+	index, status, err := breedIndexer(c.client()).Index(c.Ctx.Request.Context())
 	if err != nil {
-		c.CustomAbort(500, "Failed to fetch breed list")
+		fmt.Println("Failed to build breed index:", err)
+		c.serveError(upstreamStatus(err), "Failed to fetch breed list")
+		return
+	}
+	c.markCache(status)
+
+	c.Data["json"] = index.Search(q, limit)
+	c.ServeJSON()
+}
+
+// Number of breeds Compare accepts
+const (
+	minCompareBreeds = 2
+	maxCompareBreeds = 4
+)
+
+// Compare returns a trait-by-trait table of the breeds in ids
+func (c *BreedSearchController) Compare() {
+	var ids []string
+	seen := map[string]bool{}
+	for _, id := range strings.Split(c.GetString("ids"), ",") {
+		if id = strings.TrimSpace(id); id != "" && !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) < minCompareBreeds || len(ids) > maxCompareBreeds {
+		c.serveError(http.StatusBadRequest,
+			fmt.Sprintf("ids must list %d to %d different breeds", minCompareBreeds, maxCompareBreeds))
 		return
 	}
-	defer resp.Body.Close()
 
-	body, err := ioutil.ReadAll(resp.Body)
+	list, status, err := c.breeds().Breeds(c.Ctx.Request.Context())
 	if err != nil {
-		c.CustomAbort(500, "Failed to read API response")
+		fmt.Println("Failed to fetch breed list:", err)
+		c.serveError(upstreamStatus(err), "Failed to fetch breed list")
 		return
 	}
+	c.markCache(status)
+	byID := make(map[string]CatBreed, len(list))
+	for _, b := range list {
+		byID[b.ID] = b
+	}
 
-	var breeds []CatBreed
-	if err := json.Unmarshal(body, &breeds); err != nil {
-		c.CustomAbort(500, "Failed to parse breed list")
+	selected := make([]CatBreed, 0, len(ids))
+	for _, id := range ids {
+		breed, ok := byID[id]
+		if !ok {
+			c.serveError(http.StatusNotFound, "Breed not found: "+id)
+			return
+		}
+		selected = append(selected, breed)
+	}
+
+	c.Data["json"] = breeds.Compare(selected)
+	c.ServeJSON()
+}
+
+// Origins groups the breed list by country of origin for the map view
+func (c *BreedSearchController) Origins() {
+	list, status, err := c.breeds().Breeds(c.Ctx.Request.Context())
+	if err != nil {
+		fmt.Println("Failed to fetch breed list:", err)
+		c.serveError(upstreamStatus(err), "Failed to fetch breed list")
 		return
 	}
 
-	c.Data["json"] = breeds
+	c.markCache(status)
+	c.Data["json"] = breeds.ByOrigin(list)
 	c.ServeJSON()
 }
 
-// Fetch breed details and images concurrently
+// errBreedNotFound is reported when the requested breed does not exist
+var errBreedNotFound = errors.New("breed not found")
+
+// breedPart is the outcome of one half of the Post fan-out
+type breedPart struct {
+	breed  *CatBreed
+	status catapi.CacheStatus
+	images []BreedImage
+	page   *catapi.Pagination
+	err    error
+}
+
+// Fetch breed details and the first gallery page concurrently. A failed
+// breed lookup cancels the image search; a missing breed is a 404 and
+// upstream failures a 502. If only the image search fails, the breed's
+// reference image is served instead, marked stale. The gallery takes the
+// parameters of Images.
 func (c *BreedSearchController) Post() {
 	breedID := c.GetString("breed_id")
 	if breedID == "" {
-		c.CustomAbort(400, "Breed ID is required")
+		c.serveError(http.StatusBadRequest, "Breed ID is required")
+		return
+	}
+	search, status, msg := c.galleryQuery(breedID)
+	if status != 0 {
+		c.serveError(status, msg)
 		return
 	}
 
-	// Channels for concurrent API calls
-	breedDetailsChan := make(chan CatBreed)
-	imagesChan := make(chan []BreedImage)
-	errChan := make(chan error)
-
-	// Fetch breed details concurrently
-	go func() {
-		url := "https://api.thecatapi.com/v1/breeds"
-		req, _ := http.NewRequest("GET", url, nil)
-		req.Header.Set("x-api-key", c.APIKey)
-
-		client := &http.Client{}
-		resp, _ := client.Do(req)
-		defer resp.Body.Close()
+	ctx, cancel := context.WithCancel(c.Ctx.Request.Context())
+	defer cancel()
+	api := c.client()
+	cache := c.breeds()
 
-		var breeds []CatBreed
-		body, _ := ioutil.ReadAll(resp.Body)
-		json.Unmarshal(body, &breeds)
+	// Buffered so neither goroutine blocks once we stop listening
+	parts := make(chan breedPart, 2)
 
-		for _, breed := range breeds {
-			if breed.ID == breedID {
-				breedDetailsChan <- breed
-				return
-			}
+	go func() {
+		breed, ok, status, err := cache.Breed(ctx, breedID)
+		switch {
+		case err != nil:
+			parts <- breedPart{err: err}
+		case !ok:
+			parts <- breedPart{err: errBreedNotFound}
+		default:
+			parts <- breedPart{breed: &breed, status: status}
 		}
-		errChan <- fmt.Errorf("Breed not found")
 	}()
 
-	// Fetch breed images concurrently
 	go func() {
-		url := fmt.Sprintf("https://api.thecatapi.com/v1/images/search?breed_ids=%s&limit=8", breedID)
-		req, _ := http.NewRequest("GET", url, nil)
-		req.Header.Set("x-api-key", c.APIKey)
-
-		client := &http.Client{}
-		resp, _ := client.Do(req)
-		defer resp.Body.Close()
-
-		var images []BreedImage
-		body, _ := ioutil.ReadAll(resp.Body)
-		json.Unmarshal(body, &images)
-
-		imagesChan <- images
+		images, page, err := api.SearchImagesPage(ctx, search)
+		parts <- breedPart{images: images, page: &page, err: err}
 	}()
 
-	// Wait for results or errors
 	var selectedBreed CatBreed
+	var cacheStatus catapi.CacheStatus
 	var breedImages []BreedImage
+	var upstreamPage catapi.Pagination
+	var imagesErr error
+	for range 2 {
+		var part breedPart
+		select {
+		case part = <-parts:
+		case <-ctx.Done():
+			// The client went away; nobody is left to answer
+			return
+		}
 
-	select {
-	case selectedBreed = <-breedDetailsChan:
-	case err := <-errChan:
-		c.CustomAbort(500, err.Error())
-		return
+		if part.err != nil && part.page != nil {
+			// Wait for the breed; its reference image may stand in
+			imagesErr = part.err
+			continue
+		}
+		if part.err != nil {
+			cancel()
+			if c.Ctx.Request.Context().Err() != nil {
+				return
+			}
+			c.serveBreedError(part.err)
+			return
+		}
+		if part.breed != nil {
+			selectedBreed, cacheStatus = *part.breed, part.status
+		} else {
+			breedImages, upstreamPage = part.images, *part.page
+		}
 	}
 
-	select {
-	case breedImages = <-imagesChan:
-	case err := <-errChan:
-		c.CustomAbort(500, err.Error())
-		return
+	var page GalleryPage
+	if imagesErr != nil {
+		if c.Ctx.Request.Context().Err() != nil {
+			return
+		}
+		if selectedBreed.Image == nil {
+			c.serveBreedError(imagesErr)
+			return
+		}
+		fmt.Println("Failed to fetch breed images, serving the reference image:", imagesErr)
+		breedImages = []BreedImage{*selectedBreed.Image}
+		page = GalleryPage{Page: search.Page, Limit: search.Limit, Order: search.Order}
+		cacheStatus = catapi.CacheStale
+	} else {
+		breedImages, page = c.galleryPage(search, breedImages, upstreamPage)
 	}
+	c.markCache(cacheStatus)
 
 	// Return the combined data as JSON
 	c.Data["json"] = map[string]interface{}{
-		"breed":  selectedBreed,
-		"images": breedImages,
+		"breed":      localizeBreed(c.Ctx, selectedBreed),
+		"images":     breedImages,
+		"pagination": page,
 	}
 	c.ServeJSON()
 }
+
+// markCache marks the response with how the breed list was obtained
+func (c *BreedSearchController) markCache(status catapi.CacheStatus) {
+	markBreedCache(c.Ctx.Output, c.breeds(), status)
+}
+
+// serveBreedError answers a failed breed lookup with a matching status
+func (c *BreedSearchController) serveBreedError(err error) {
+	if errors.Is(err, errBreedNotFound) {
+		c.serveError(http.StatusNotFound, "Breed not found")
+		return
+	}
+	fmt.Println("Failed to fetch breed details:", err)
+	c.serveError(upstreamStatus(err), "Failed to fetch breed details")
+}
+
+func (c *BreedSearchController) serveError(status int, msg string) {
+	c.Ctx.Output.SetStatus(status)
+	c.Data["json"] = errorBody(c.Ctx, msg)
+	c.ServeJSON()
+}
@@ -0,0 +1,179 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/beego/beego/v2/server/web"
+	beecontext "github.com/beego/beego/v2/server/web/context"
+
+	"myproject/breeds"
+	"myproject/catapi"
+	"myproject/snapshot"
+)
+
+// defaultBreedCacheTTL is used when breed_cache_ttl is not configured.
+const defaultBreedCacheTTL = time.Hour
+
+// defaultBreedIndexRefresh is used when breed_index_refresh is not configured.
+const defaultBreedIndexRefresh = 10 * time.Minute
+
+// Random image pool defaults when image_pool_size and image_pool_batch are
+// not configured.
+const (
+	defaultImagePoolSize  = 20
+	defaultImagePoolBatch = 10
+)
+
+var (
+	catAPIMu      sync.Mutex
+	catAPIClients = map[string]*catapi.Client{}
+	breedCaches   = map[*catapi.Client]*catapi.BreedCache{}
+	breedIndexers = map[*catapi.Client]*breeds.Indexer{}
+	imagePools    = map[*catapi.Client]*catapi.ImagePool{}
+)
+
+// catAPIClient returns the shared Cat API client for apiKey, creating it
+// on first use. The base URL comes from catapi_base_url in app.conf and
+// defaults to the public API; retries and the circuit breaker are tuned by
+// the catapi_max_attempts and catapi_breaker_* settings.
+func catAPIClient(apiKey string) *catapi.Client {
+	baseURL := web.AppConfig.DefaultString("catapi_base_url", catapi.DefaultBaseURL)
+	key := baseURL + "|" + apiKey
+
+	catAPIMu.Lock()
+	defer catAPIMu.Unlock()
+
+	if client, ok := catAPIClients[key]; ok {
+		return client
+	}
+	retry := catapi.DefaultRetryPolicy
+	retry.MaxAttempts = web.AppConfig.DefaultInt("catapi_max_attempts", retry.MaxAttempts)
+	client := catapi.New(apiKey,
+		catapi.WithBaseURL(baseURL),
+		catapi.WithRetry(retry),
+		catapi.WithCircuitBreaker(
+			web.AppConfig.DefaultInt("catapi_breaker_threshold", catapi.DefaultBreakerThreshold),
+			configDuration("catapi_breaker_cooldown", catapi.DefaultBreakerCooldown),
+		),
+	)
+	catAPIClients[key] = client
+	return client
+}
+
+// breedCache returns the shared breed cache in front of client, creating
+// it on first use with the TTL from breed_cache_ttl in app.conf. Unless
+// breed_snapshot_fallback is false, the cache falls back to the breed
+// snapshot while the upstream is unreachable.
+func breedCache(client *catapi.Client) *catapi.BreedCache {
+	catAPIMu.Lock()
+	defer catAPIMu.Unlock()
+
+	if cache, ok := breedCaches[client]; ok {
+		return cache
+	}
+	var opts []catapi.CacheOption
+	if web.AppConfig.DefaultBool("breed_snapshot_fallback", true) {
+		snap, err := breedSnapshot()
+		switch {
+		case err != nil:
+			fmt.Println("Failed to load breed snapshot:", err)
+		case len(snap.Breeds) > 0:
+			opts = append(opts, catapi.WithSnapshot(snap.Breeds, snap.TakenAt))
+		default:
+			fmt.Println("Breed snapshot is empty; breed search has no fallback. Run cmd/breedsnapshot and rebuild.")
+		}
+	}
+	cache := catapi.NewBreedCache(client, configDuration("breed_cache_ttl", defaultBreedCacheTTL), opts...)
+	breedCaches[client] = cache
+	return cache
+}
+
+// breedSnapshot reads the file named by breed_snapshot_file in app.conf,
+// or the snapshot embedded in the binary when it is not set.
+func breedSnapshot() (*snapshot.Snapshot, error) {
+	if path := web.AppConfig.DefaultString("breed_snapshot_file", ""); path != "" {
+		return snapshot.Load(path)
+	}
+	return snapshot.Embedded()
+}
+
+// markBreedCache reports in X-Cache how cache answered. Stale answers also
+// carry a Warning header, plus X-Breeds-Snapshot with the time the
+// snapshot was taken when they come from it.
+func markBreedCache(output *beecontext.BeegoOutput, cache *catapi.BreedCache, status catapi.CacheStatus) {
+	output.Header("X-Cache", string(status))
+	if status != catapi.CacheStale {
+		return
+	}
+	output.Header("Warning", `110 - "Response is Stale"`)
+	if stats := cache.Stats(); stats.Snapshot {
+		output.Header("X-Breeds-Snapshot", stats.FetchedAt.UTC().Format(time.RFC3339))
+	}
+}
+
+// breedIndexer returns the shared search index over client's cached breed
+// list, rebuilt every breed_index_refresh from app.conf.
+func breedIndexer(client *catapi.Client) *breeds.Indexer {
+	cache := breedCache(client)
+
+	catAPIMu.Lock()
+	defer catAPIMu.Unlock()
+
+	if indexer, ok := breedIndexers[client]; ok {
+		return indexer
+	}
+	indexer := breeds.NewIndexer(cache.Breeds, configDuration("breed_index_refresh", defaultBreedIndexRefresh))
+	breedIndexers[client] = indexer
+	return indexer
+}
+
+// imagePool returns the shared pool of random images from client, creating
+// it on first use. It holds image_pool_size images from app.conf, refilled
+// image_pool_batch at a time; a size of 0 turns it off.
+func imagePool(client *catapi.Client) *catapi.ImagePool {
+	catAPIMu.Lock()
+	defer catAPIMu.Unlock()
+
+	if pool, ok := imagePools[client]; ok {
+		return pool
+	}
+	pool := catapi.NewImagePool(client,
+		web.AppConfig.DefaultInt("image_pool_size", defaultImagePoolSize),
+		web.AppConfig.DefaultInt("image_pool_batch", defaultImagePoolBatch),
+	)
+	imagePools[client] = pool
+	return pool
+}
+
+// upstreamStatus maps a Cat API error to the status we answer with: 404
+// for missing resources, 503 while the circuit breaker is open and 502 for
+// any other upstream failure.
+func upstreamStatus(err error) int {
+	switch {
+	case catapi.IsNotFound(err):
+		return http.StatusNotFound
+	case errors.Is(err, catapi.ErrCircuitOpen):
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusBadGateway
+	}
+}
+
+// configDuration reads a duration such as "15m" from app.conf, falling
+// back to def when the key is missing or invalid.
+func configDuration(key string, def time.Duration) time.Duration {
+	value := web.AppConfig.DefaultString(key, "")
+	if value == "" {
+		return def
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		fmt.Printf("Invalid %s %q, using %s: %v\n", key, value, def, err)
+		return def
+	}
+	return d
+}
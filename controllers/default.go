@@ -2,6 +2,8 @@ package controllers
 
 import (
 	beego "github.com/beego/beego/v2/server/web"
+
+	"myproject/i18n"
 )
 
 type MainController struct {
@@ -9,5 +11,8 @@ type MainController struct {
 }
 
 func (c *MainController) Get() {
+	c.Data["Lang"] = requestLang(c.Ctx)
+	c.Data["Languages"] = i18n.Supported
+	c.Data["LanguageNames"] = i18n.Names
 	c.TplName = "index.tpl"
 }
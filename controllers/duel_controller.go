@@ -0,0 +1,241 @@
+package controllers
+
+import (
+	"context"
+	"encoding/gob"
+	"fmt"
+	"hash/fnv"
+	"net/http"
+	"sync"
+
+	"github.com/beego/beego/v2/server/web"
+
+	"myproject/catapi"
+	"myproject/elo"
+	"myproject/models"
+)
+
+// Duel ranking page sizes
+const (
+	defaultDuelRankingLimit = 20
+	maxDuelRankingLimit     = 100
+)
+
+// duelSessionKey holds the duelPair last served to the session
+const duelSessionKey = "duel"
+
+// duelPair is the two images of a duel. Only the pair a session was shown
+// can be voted on, so ratings can't be pushed around by arbitrary IDs.
+type duelPair struct {
+	A, B string
+}
+
+func init() {
+	// Session providers other than memory store values with gob
+	gob.Register(duelPair{})
+}
+
+var (
+	ratingStoreOnce sync.Once
+	ratingStore     *models.RatingStore
+	ratingStoreErr  error
+)
+
+// defaultRatingStore opens the rating store configured by rating_store in
+// app.conf once per process, moving ratings by duel_k_factor per duel.
+func defaultRatingStore() (*models.RatingStore, error) {
+	ratingStoreOnce.Do(func() {
+		path := web.AppConfig.DefaultString("rating_store", "data/ratings.json")
+		k := web.AppConfig.DefaultFloat("duel_k_factor", elo.DefaultK)
+		ratingStore, ratingStoreErr = models.NewRatingStore(path, k)
+	})
+	return ratingStore, ratingStoreErr
+}
+
+// DuelController serves the "which cat is cuter" voting mode
+type DuelController struct {
+	web.Controller
+	APIKey  string
+	API     *catapi.Client
+	Ratings *models.RatingStore
+}
+
+// DuelImage is one contender of a duel with its current rating
+type DuelImage struct {
+	catapi.Image
+	Rating float64 `json:"rating"`
+}
+
+// DuelRank is an image's place in the duel ranking
+type DuelRank struct {
+	Rank int `json:"rank"`
+	models.Rating
+	ImageURL string `json:"image_url"`
+}
+
+func (c *DuelController) Prepare() {
+	if c.APIKey == "" {
+		apiKey, err := web.AppConfig.String("api_key")
+		if err != nil || apiKey == "" {
+			c.CustomAbort(http.StatusInternalServerError, "API key is not configured")
+		}
+		c.APIKey = apiKey
+	}
+}
+
+// client returns the injected Cat API client or the shared one for c.APIKey
+func (c *DuelController) client() *catapi.Client {
+	if c.API == nil {
+		c.API = catAPIClient(c.APIKey)
+	}
+	return c.API
+}
+
+// ratings returns the injected store or the process-wide one
+func (c *DuelController) ratings() (*models.RatingStore, error) {
+	if c.Ratings != nil {
+		return c.Ratings, nil
+	}
+	return defaultRatingStore()
+}
+
+// fetchDuelImages returns two different random images
+func fetchDuelImages(ctx context.Context, api *catapi.Client) ([]catapi.Image, error) {
+	// A random page can repeat an image, so give it a second chance
+	for range 2 {
+		images, _, err := api.SearchImagesPage(ctx, catapi.ImageSearch{Limit: 2})
+		if err != nil {
+			return nil, err
+		}
+		for i := 1; i < len(images); i++ {
+			if images[i].ID != images[0].ID {
+				return []catapi.Image{images[0], images[i]}, nil
+			}
+		}
+	}
+	return nil, errNoImage
+}
+
+// Get serves two random images to choose between, with their ratings
+func (c *DuelController) Get() {
+	ctx := c.Ctx.Request.Context()
+	images, err := fetchDuelImages(ctx, c.client())
+	if ctx.Err() != nil {
+		return // client disconnected
+	}
+	if err != nil {
+		fmt.Println("Failed to fetch cat images:", err)
+		c.serveError(upstreamStatus(err), "Failed to fetch cat images")
+		return
+	}
+	store, err := c.ratings()
+	if err != nil {
+		fmt.Println("Failed to open rating store:", err)
+		c.serveError(http.StatusInternalServerError, "Rating store is unavailable")
+		return
+	}
+
+	if c.CruSession != nil || c.Ctx.Input.CruSession != nil {
+		c.SetSession(duelSessionKey, duelPair{A: images[0].ID, B: images[1].ID})
+	}
+	duel := make([]DuelImage, len(images))
+	for i, img := range images {
+		duel[i] = DuelImage{Image: img, Rating: store.Get(img.ID).Rating}
+	}
+	c.Data["json"] = map[string]interface{}{"images": duel}
+	c.ServeJSON()
+}
+
+// Post records the logged-in user's pick of winner_id over loser_id, which
+// must be the two images of the duel last served to them, and responds
+// with both new ratings. Each duel counts once.
+func (c *DuelController) Post() {
+	userID := requireUser(&c.Controller)
+	if userID == "" {
+		return
+	}
+
+	winnerID, loserID := c.GetString("winner_id"), c.GetString("loser_id")
+	pair, ok := c.claimDuel(userID, winnerID, loserID)
+	if !ok {
+		c.serveError(http.StatusBadRequest, "Pick one of the two images of your current duel")
+		return
+	}
+
+	store, err := c.ratings()
+	if err != nil {
+		fmt.Println("Failed to open rating store:", err)
+		c.SetSession(duelSessionKey, pair)
+		c.serveError(http.StatusInternalServerError, "Rating store is unavailable")
+		return
+	}
+	winner, loser, err := store.RecordDuel(winnerID, loserID)
+	if err != nil {
+		fmt.Println("Failed to record duel:", err)
+		// Let the user pick again
+		c.SetSession(duelSessionKey, pair)
+		c.serveError(http.StatusInternalServerError, "Failed to record duel")
+		return
+	}
+
+	c.Data["json"] = map[string]interface{}{"winner": winner, "loser": loser}
+	c.ServeJSON()
+}
+
+// duelLocks serialise claiming a user's duels. They are striped so the
+// set stays bounded.
+var duelLocks [64]sync.Mutex
+
+// claimDuel removes the session's current duel and returns it if winnerID
+// and loserID are its two images. Concurrent posts for the same duel
+// can't both claim it.
+func (c *DuelController) claimDuel(userID, winnerID, loserID string) (duelPair, bool) {
+	h := fnv.New32a()
+	h.Write([]byte(userID))
+	lock := &duelLocks[h.Sum32()%uint32(len(duelLocks))]
+	lock.Lock()
+	defer lock.Unlock()
+
+	pair, ok := c.GetSession(duelSessionKey).(duelPair)
+	if !ok || winnerID == loserID ||
+		!(winnerID == pair.A && loserID == pair.B || winnerID == pair.B && loserID == pair.A) {
+		return duelPair{}, false
+	}
+	c.DelSession(duelSessionKey)
+	return pair, true
+}
+
+// Ranking lists up to limit of the highest rated images with their URLs
+func (c *DuelController) Ranking() {
+	limit, err := c.GetInt("limit", defaultDuelRankingLimit)
+	if err != nil || limit < 1 || limit > maxDuelRankingLimit {
+		c.serveError(http.StatusBadRequest, fmt.Sprintf("limit must be between 1 and %d", maxDuelRankingLimit))
+		return
+	}
+	store, err := c.ratings()
+	if err != nil {
+		fmt.Println("Failed to open rating store:", err)
+		c.serveError(http.StatusInternalServerError, "Rating store is unavailable")
+		return
+	}
+
+	top := store.Top(limit)
+	ids := make([]string, len(top))
+	for i, r := range top {
+		ids[i] = r.ImageID
+	}
+	images := lookupImages(c.Ctx.Request.Context(), c.client(), ids)
+
+	ranking := make([]DuelRank, len(top))
+	for i, r := range top {
+		ranking[i] = DuelRank{Rank: i + 1, Rating: r, ImageURL: images[r.ImageID].URL}
+	}
+	c.Data["json"] = ranking
+	c.ServeJSON()
+}
+
+func (c *DuelController) serveError(status int, msg string) {
+	c.Ctx.Output.SetStatus(status)
+	c.Data["json"] = errorBody(c.Ctx, msg)
+	c.ServeJSON()
+}
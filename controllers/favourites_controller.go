@@ -1,27 +1,23 @@
 package controllers
 
 import (
-	"encoding/json"
+	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"github.com/beego/beego/v2/server/web"
+
+	"myproject/catapi"
 )
 
 type FavoritesController struct {
 	web.Controller
 	APIKey string
+	API    *catapi.Client
 }
 
-type FavoriteResponse struct {
-	ID        int    `json:"id"`
-	ImageID   string `json:"image_id"`
-	SubID     string `json:"sub_id"`
-	CreatedAt string `json:"created_at"`
-	Image     struct {
-		ID  string `json:"id"`
-		URL string `json:"url"`
-	} `json:"image"`
-}
+type FavoriteResponse = catapi.Favourite
 
 func (c *FavoritesController) Prepare() {
 	// Use APIKey if provided; fallback to Beego config
@@ -34,36 +30,122 @@ func (c *FavoritesController) Prepare() {
 	}
 }
 
-// Get retrieves all favorites
+// client returns the injected Cat API client or the shared one for c.APIKey
+func (c *FavoritesController) client() *catapi.Client {
+	if c.API == nil {
+		c.API = catAPIClient(c.APIKey)
+	}
+	return c.API
+}
+
+// maxFavoritesLimit mirrors the upstream cap on page size
+const maxFavoritesLimit = 100
+
+// favoritesQuery reads page, limit, order and sub_id from the request.
+// sub_id defaults to the caller and may not name anyone else.
+func (c *FavoritesController) favoritesQuery(userID string) (catapi.FavouriteQuery, int, string) {
+	query := catapi.FavouriteQuery{SubID: userID}
+
+	if subID := c.GetString("sub_id"); subID != "" && subID != userID {
+		return query, http.StatusForbidden, "Cannot list another user's favorites"
+	}
+
+	page, err := c.GetInt("page", 0)
+	if err != nil || page < 0 {
+		return query, http.StatusBadRequest, "page must be a non-negative integer"
+	}
+	limit, err := c.GetInt("limit", 0)
+	if err != nil || limit < 0 || limit > maxFavoritesLimit {
+		return query, http.StatusBadRequest, fmt.Sprintf("limit must be between 1 and %d", maxFavoritesLimit)
+	}
+	order := strings.ToUpper(c.GetString("order"))
+	if order != "" && order != "ASC" && order != "DESC" {
+		return query, http.StatusBadRequest, "order must be ASC or DESC"
+	}
+
+	query.Page, query.Limit, query.Order = page, limit, order
+	return query, 0, ""
+}
+
+// Get retrieves a page of the logged-in user's favorites. Paging metadata
+// from the upstream is passed on in the Pagination-* response headers.
 func (c *FavoritesController) Get() {
-	// Create HTTP request
-	req, err := http.NewRequest("GET", "https://api.thecatapi.com/v1/favourites", nil)
+	userID := requireUser(&c.Controller)
+	if userID == "" {
+		return
+	}
+
+	query, status, msg := c.favoritesQuery(userID)
+	if status != 0 {
+		c.serveError(status, msg)
+		return
+	}
+
+	favorites, page, err := c.client().ListFavourites(c.Ctx.Request.Context(), query)
 	if err != nil {
-		c.Data["json"] = map[string]interface{}{"error": "Failed to create request"}
-		c.ServeJSON()
+		fmt.Println("Failed to fetch favorites:", err)
+		c.serveError(upstreamStatus(err), "Failed to fetch favorites")
 		return
 	}
 
-	req.Header.Set("x-api-key", c.APIKey)
+	// Only forward paging metadata the upstream actually reported
+	if page.Limit > 0 {
+		c.Ctx.Output.Header("Pagination-Count", strconv.Itoa(page.Count))
+		c.Ctx.Output.Header("Pagination-Page", strconv.Itoa(page.Page))
+		c.Ctx.Output.Header("Pagination-Limit", strconv.Itoa(page.Limit))
+	}
+	c.Data["json"] = favorites
+	c.ServeJSON()
+}
 
-	// Send request
-	client := &http.Client{}
-	resp, err := client.Do(req)
+func (c *FavoritesController) serveError(status int, msg string) {
+	c.Ctx.Output.SetStatus(status)
+	c.Data["json"] = errorBody(c.Ctx, msg)
+	c.ServeJSON()
+}
+
+// Delete removes one of the logged-in user's favorites
+func (c *FavoritesController) Delete() {
+	userID := requireUser(&c.Controller)
+	if userID == "" {
+		return
+	}
+
+	id, err := strconv.Atoi(c.Ctx.Input.Param(":id"))
 	if err != nil {
-		c.Data["json"] = map[string]interface{}{"error": "Failed to fetch favorites"}
-		c.ServeJSON()
+		c.serveError(http.StatusBadRequest, "Invalid favorite ID")
 		return
 	}
-	defer resp.Body.Close()
 
-	// Parse response
-	var favorites []FavoriteResponse
-	if err := json.NewDecoder(resp.Body).Decode(&favorites); err != nil {
-		c.Data["json"] = map[string]interface{}{"error": "Failed to parse response"}
-		c.ServeJSON()
+	ctx := c.Ctx.Request.Context()
+	api := c.client()
+
+	// Only the owner may remove a favorite, so look it up first
+	favorite, err := api.GetFavourite(ctx, id)
+	if catapi.IsNotFound(err) {
+		c.serveError(http.StatusNotFound, "Favorite not found")
+		return
+	}
+	if err != nil {
+		fmt.Println("Failed to fetch favorite:", err)
+		c.serveError(upstreamStatus(err), "Failed to fetch favorite")
+		return
+	}
+	if favorite.SubID != userID {
+		c.serveError(http.StatusForbidden, "Favorite belongs to another user")
 		return
 	}
 
-	c.Data["json"] = favorites
+	if err := api.DeleteFavourite(ctx, id); err != nil {
+		if catapi.IsNotFound(err) {
+			c.serveError(http.StatusNotFound, "Favorite not found")
+			return
+		}
+		fmt.Println("Failed to delete favorite:", err)
+		c.serveError(upstreamStatus(err), "Failed to delete favorite")
+		return
+	}
+
+	c.Data["json"] = map[string]interface{}{"id": id, "status": "deleted"}
 	c.ServeJSON()
 }
@@ -0,0 +1,95 @@
+package controllers
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/beego/beego/v2/server/web"
+	beecontext "github.com/beego/beego/v2/server/web/context"
+
+	"myproject/catapi"
+	"myproject/i18n"
+)
+
+// langCookie remembers a language picked with the lang query parameter
+const langCookie = "lang"
+
+// langCookieMaxAge keeps the language choice for a year
+const langCookieMaxAge = 365 * 24 * 60 * 60
+
+// langDataKey is where requestLang keeps the language of a request
+const langDataKey = "i18n.lang"
+
+var (
+	descriptionsMu  sync.Mutex
+	descriptionsKey string
+	descriptions    *i18n.Descriptions
+)
+
+func init() {
+	web.AddFuncMap("t", i18n.T)
+}
+
+// requestLang returns the language to answer ctx in: the lang query
+// parameter, which is also remembered in a cookie, then that cookie, then
+// the best match for Accept-Language, then English. The choice is
+// announced in Content-Language.
+func requestLang(ctx *beecontext.Context) string {
+	if lang, ok := ctx.Input.GetData(langDataKey).(string); ok {
+		return lang
+	}
+
+	preferred, ok := i18n.Match(ctx.Input.Query("lang"))
+	if ok {
+		ctx.SetCookie(langCookie, preferred, langCookieMaxAge, "/")
+	} else {
+		preferred = ctx.GetCookie(langCookie)
+	}
+	lang := i18n.Negotiate(preferred, ctx.Input.Header("Accept-Language"))
+
+	ctx.Input.SetData(langDataKey, lang)
+	ctx.Output.Header("Content-Language", lang)
+	ctx.Output.Header("Vary", "Accept-Language, Cookie")
+	return lang
+}
+
+// errorBody is the JSON body of an error response, with msg translated
+// into the request's language
+func errorBody(ctx *beecontext.Context, msg string) map[string]interface{} {
+	return map[string]interface{}{"error": i18n.Error(requestLang(ctx), msg)}
+}
+
+// breedDescriptions returns the shared breed description localizer. Hand
+// written translations are read from description_overrides in app.conf;
+// when translate_url is set, other descriptions are machine translated by
+// the LibreTranslate server there, with translate_api_key.
+func breedDescriptions() *i18n.Descriptions {
+	path := web.AppConfig.DefaultString("description_overrides", "data/descriptions.json")
+	translateURL := web.AppConfig.DefaultString("translate_url", "")
+	apiKey := web.AppConfig.DefaultString("translate_api_key", "")
+	key := path + "|" + translateURL + "|" + apiKey
+
+	descriptionsMu.Lock()
+	defer descriptionsMu.Unlock()
+
+	if descriptions != nil && descriptionsKey == key {
+		return descriptions
+	}
+	overrides, err := i18n.LoadOverrides(path)
+	if err != nil {
+		fmt.Println("Failed to load description overrides:", err)
+	}
+	var source i18n.Translator
+	if translateURL != "" {
+		source = &i18n.LibreTranslate{URL: translateURL, APIKey: apiKey}
+	}
+	descriptions, descriptionsKey = i18n.NewDescriptions(overrides, source), key
+	return descriptions
+}
+
+// localizeBreed returns breed with its description in the request's
+// language
+func localizeBreed(ctx *beecontext.Context, breed catapi.Breed) catapi.Breed {
+	breed.Description = breedDescriptions().Describe(ctx.Request.Context(), requestLang(ctx), breed)
+	return breed
+}
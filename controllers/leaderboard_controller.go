@@ -0,0 +1,171 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/beego/beego/v2/server/web"
+
+	"myproject/catapi"
+	"myproject/models"
+)
+
+// Leaderboard page sizes
+const (
+	defaultLeaderboardLimit = 20
+	maxLeaderboardLimit     = 100
+)
+
+// leaderboardWindows are how far back each leaderboard window reaches;
+// zero means all time
+var leaderboardWindows = map[string]time.Duration{
+	"day":  24 * time.Hour,
+	"week": 7 * 24 * time.Hour,
+	"all":  0,
+}
+
+var (
+	voteStoreOnce sync.Once
+	voteStore     *models.VoteStore
+	voteStoreErr  error
+)
+
+// defaultVoteStore opens the vote store configured by vote_store in
+// app.conf once per process.
+func defaultVoteStore() (*models.VoteStore, error) {
+	voteStoreOnce.Do(func() {
+		path := web.AppConfig.DefaultString("vote_store", "data/votes.jsonl")
+		voteStore, voteStoreErr = models.NewVoteStore(path)
+	})
+	return voteStore, voteStoreErr
+}
+
+// localVotes returns the injected store or the process-wide one. Votes
+// are only kept locally for the leaderboard, so a store that cannot be
+// opened is logged and nil returned.
+func localVotes(injected *models.VoteStore) *models.VoteStore {
+	if injected != nil {
+		return injected
+	}
+	store, err := defaultVoteStore()
+	if err != nil {
+		fmt.Println("Failed to open vote store:", err)
+		return nil
+	}
+	return store
+}
+
+// recordVote keeps v for the leaderboard. The vote already counts
+// upstream, so failures are only logged.
+func recordVote(store *models.VoteStore, v models.Vote) {
+	if store == nil {
+		return
+	}
+	if err := store.Add(v); err != nil {
+		fmt.Println("Failed to record vote locally:", err)
+	}
+}
+
+// forgetVote drops the vote with id from the leaderboard
+func forgetVote(store *models.VoteStore, id int) {
+	if store == nil {
+		return
+	}
+	if err := store.Remove(id); err != nil {
+		fmt.Println("Failed to forget vote locally:", err)
+	}
+}
+
+// LeaderboardController ranks images by the votes cast through the app
+type LeaderboardController struct {
+	web.Controller
+	APIKey string
+	API    *catapi.Client
+	Votes  *models.VoteStore
+}
+
+// LeaderboardEntry is an image's place on the leaderboard. Images with
+// the same score share a rank.
+type LeaderboardEntry struct {
+	Rank int `json:"rank"`
+	models.ImageScore
+	ImageURL string `json:"image_url"`
+}
+
+func (c *LeaderboardController) Prepare() {
+	if c.APIKey == "" {
+		apiKey, err := web.AppConfig.String("api_key")
+		if err != nil || apiKey == "" {
+			c.CustomAbort(http.StatusInternalServerError, "API key is not configured")
+		}
+		c.APIKey = apiKey
+	}
+}
+
+// client returns the injected Cat API client or the shared one for c.APIKey
+func (c *LeaderboardController) client() *catapi.Client {
+	if c.API == nil {
+		c.API = catAPIClient(c.APIKey)
+	}
+	return c.API
+}
+
+// Get ranks the images with the most net likes over window (day, week or
+// all, default week), up to limit of them.
+func (c *LeaderboardController) Get() {
+	window := strings.ToLower(c.GetString("window", "week"))
+	span, ok := leaderboardWindows[window]
+	if !ok {
+		c.serveError(http.StatusBadRequest, "window must be day, week or all")
+		return
+	}
+	limit, err := c.GetInt("limit", defaultLeaderboardLimit)
+	if err != nil || limit < 1 || limit > maxLeaderboardLimit {
+		c.serveError(http.StatusBadRequest, fmt.Sprintf("limit must be between 1 and %d", maxLeaderboardLimit))
+		return
+	}
+
+	var since *time.Time
+	var scores []models.ImageScore
+	if span > 0 {
+		start := time.Now().UTC().Add(-span)
+		since = &start
+	}
+	if store := localVotes(c.Votes); store != nil {
+		var from time.Time
+		if since != nil {
+			from = *since
+		}
+		scores = store.Leaderboard(from, limit)
+	}
+
+	ids := make([]string, len(scores))
+	for i, score := range scores {
+		ids[i] = score.ImageID
+	}
+	images := lookupImages(c.Ctx.Request.Context(), c.client(), ids)
+
+	entries := make([]LeaderboardEntry, len(scores))
+	for i, score := range scores {
+		entries[i] = LeaderboardEntry{Rank: i + 1, ImageScore: score, ImageURL: images[score.ImageID].URL}
+		if i > 0 && score.Score == scores[i-1].Score {
+			entries[i].Rank = entries[i-1].Rank
+		}
+	}
+
+	c.Data["json"] = map[string]interface{}{
+		"window":  window,
+		"since":   since,
+		"entries": entries,
+	}
+	c.ServeJSON()
+}
+
+func (c *LeaderboardController) serveError(status int, msg string) {
+	c.Ctx.Output.SetStatus(status)
+	c.Data["json"] = errorBody(c.Ctx, msg)
+	c.ServeJSON()
+}
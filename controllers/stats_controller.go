@@ -0,0 +1,44 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/beego/beego/v2/server/web"
+
+	"myproject/catapi"
+)
+
+// StatsController exposes in-process counters for debugging.
+type StatsController struct {
+	web.Controller
+	APIKey string
+	API    *catapi.Client
+}
+
+func (c *StatsController) Prepare() {
+	if c.APIKey == "" {
+		apiKey, err := web.AppConfig.String("api_key")
+		if err != nil || apiKey == "" {
+			c.CustomAbort(http.StatusInternalServerError, "API key is not configured")
+		}
+		c.APIKey = apiKey
+	}
+}
+
+// client returns the injected Cat API client or the shared one for c.APIKey
+func (c *StatsController) client() *catapi.Client {
+	if c.API == nil {
+		c.API = catAPIClient(c.APIKey)
+	}
+	return c.API
+}
+
+// Get returns the counters of the shared upstream helpers
+func (c *StatsController) Get() {
+	c.Data["json"] = map[string]interface{}{
+		"catapi":      c.client().Stats(),
+		"breed_cache": breedCache(c.client()).Stats(),
+		"image_pool":  imagePool(c.client()).Stats(),
+	}
+	c.ServeJSON()
+}
@@ -0,0 +1,360 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/beego/beego/v2/server/web"
+
+	"myproject/catapi"
+	"myproject/models"
+)
+
+// VotesController shows the logged-in user's voting history
+type VotesController struct {
+	web.Controller
+	APIKey string
+	API    *catapi.Client
+	Votes  *models.VoteStore
+}
+
+type VoteResponse = catapi.Vote
+
+// maxVotesLimit mirrors the upstream cap on page size
+const maxVotesLimit = 100
+
+// maxImageVoteScan bounds how much of a user's history is read to find
+// their votes on one image
+const maxImageVoteScan = 1000
+
+// imageLookups bounds the concurrent image lookups of lookupImages
+const imageLookups = 4
+
+// defaultVoteUndoWindow is used when vote_undo_window is not configured
+const defaultVoteUndoWindow = 10 * time.Second
+
+// voteLocks serialise changes to one user's votes on one image. They are
+// striped so the set stays bounded.
+var voteLocks [64]sync.Mutex
+
+// voteLock returns the lock for userID's votes on imageID
+func voteLock(userID, imageID string) *sync.Mutex {
+	h := fnv.New32a()
+	h.Write([]byte(userID + "|" + imageID))
+	return &voteLocks[h.Sum32()%uint32(len(voteLocks))]
+}
+
+// voteUndoWindow is how long the voting UI offers to undo or change a
+// vote, from vote_undo_window in app.conf
+func voteUndoWindow() time.Duration {
+	return configDuration("vote_undo_window", defaultVoteUndoWindow)
+}
+
+func (c *VotesController) Prepare() {
+	if c.APIKey == "" {
+		apiKey, err := web.AppConfig.String("api_key")
+		if err != nil || apiKey == "" {
+			c.CustomAbort(http.StatusInternalServerError, "API key is not configured")
+		}
+		c.APIKey = apiKey
+	}
+}
+
+// client returns the injected Cat API client or the shared one for c.APIKey
+func (c *VotesController) client() *catapi.Client {
+	if c.API == nil {
+		c.API = catAPIClient(c.APIKey)
+	}
+	return c.API
+}
+
+// votesQuery reads page, limit, order and sub_id from the request.
+// sub_id defaults to the caller and may not name anyone else.
+func (c *VotesController) votesQuery(userID string) (catapi.VoteQuery, int, string) {
+	query := catapi.VoteQuery{SubID: userID}
+
+	if subID := c.GetString("sub_id"); subID != "" && subID != userID {
+		return query, http.StatusForbidden, "Cannot list another user's votes"
+	}
+
+	page, err := c.GetInt("page", 0)
+	if err != nil || page < 0 {
+		return query, http.StatusBadRequest, "page must be a non-negative integer"
+	}
+	limit, err := c.GetInt("limit", 0)
+	if err != nil || limit < 0 || limit > maxVotesLimit {
+		return query, http.StatusBadRequest, fmt.Sprintf("limit must be between 1 and %d", maxVotesLimit)
+	}
+	order := strings.ToUpper(c.GetString("order"))
+	if order != "" && order != "ASC" && order != "DESC" {
+		return query, http.StatusBadRequest, "order must be ASC or DESC"
+	}
+
+	query.Page, query.Limit, query.Order = page, limit, order
+	return query, 0, ""
+}
+
+// Get retrieves a page of the logged-in user's votes, each with its
+// image. Paging metadata from the upstream is passed on in the
+// Pagination-* response headers. With image_id, all of the user's votes
+// on that image are listed instead of a page.
+func (c *VotesController) Get() {
+	userID := requireUser(&c.Controller)
+	if userID == "" {
+		return
+	}
+
+	query, status, msg := c.votesQuery(userID)
+	if status != 0 {
+		c.serveError(status, msg)
+		return
+	}
+
+	ctx := c.Ctx.Request.Context()
+	api := c.client()
+	var votes []catapi.Vote
+	var page catapi.Pagination
+	var err error
+	if imageID := c.GetString("image_id"); imageID != "" {
+		votes, err = imageVotes(ctx, api, query, imageID)
+	} else {
+		votes, page, err = api.ListVotes(ctx, query)
+	}
+	if err != nil {
+		fmt.Println("Failed to fetch votes:", err)
+		c.serveError(upstreamStatus(err), "Failed to fetch votes")
+		return
+	}
+	joinVoteImages(ctx, api, votes)
+
+	// Only forward paging metadata the upstream actually reported
+	if page.Limit > 0 {
+		c.Ctx.Output.Header("Pagination-Count", strconv.Itoa(page.Count))
+		c.Ctx.Output.Header("Pagination-Page", strconv.Itoa(page.Page))
+		c.Ctx.Output.Header("Pagination-Limit", strconv.Itoa(page.Limit))
+	}
+	if votes == nil {
+		votes = []catapi.Vote{}
+	}
+	c.Data["json"] = votes
+	c.ServeJSON()
+}
+
+// ownVote looks up the vote in the :id route parameter and makes sure it
+// belongs to userID. On failure it answers the request and returns nil.
+func (c *VotesController) ownVote(userID string) *catapi.Vote {
+	id, err := strconv.Atoi(c.Ctx.Input.Param(":id"))
+	if err != nil {
+		c.serveError(http.StatusBadRequest, "Invalid vote ID")
+		return nil
+	}
+
+	vote, err := c.client().GetVote(c.Ctx.Request.Context(), id)
+	if catapi.IsNotFound(err) {
+		c.serveError(http.StatusNotFound, "Vote not found")
+		return nil
+	}
+	if err != nil {
+		fmt.Println("Failed to fetch vote:", err)
+		c.serveError(upstreamStatus(err), "Failed to fetch vote")
+		return nil
+	}
+	if vote.SubID != userID {
+		c.serveError(http.StatusForbidden, "Vote belongs to another user")
+		return nil
+	}
+	return vote
+}
+
+// Delete removes one of the logged-in user's votes, e.g. to undo it
+func (c *VotesController) Delete() {
+	userID := requireUser(&c.Controller)
+	if userID == "" {
+		return
+	}
+	vote := c.ownVote(userID)
+	if vote == nil {
+		return
+	}
+
+	lock := voteLock(userID, vote.ImageID)
+	lock.Lock()
+	defer lock.Unlock()
+
+	if err := c.client().DeleteVote(c.Ctx.Request.Context(), vote.ID); err != nil {
+		if catapi.IsNotFound(err) {
+			c.serveError(http.StatusNotFound, "Vote not found")
+			return
+		}
+		fmt.Println("Failed to delete vote:", err)
+		c.serveError(upstreamStatus(err), "Failed to delete vote")
+		return
+	}
+
+	forgetVote(localVotes(c.Votes), vote.ID)
+
+	c.Data["json"] = map[string]interface{}{"id": vote.ID, "status": "deleted"}
+	c.ServeJSON()
+}
+
+// Put changes one of the logged-in user's votes to the action in value,
+// like or dislike. The upstream cannot update votes, so a vote with the
+// new value replaces the old one; if the old vote cannot be removed the
+// new one is taken back, leaving the user with exactly one vote either way.
+func (c *VotesController) Put() {
+	userID := requireUser(&c.Controller)
+	if userID == "" {
+		return
+	}
+	value, ok := voteValues[c.GetString("value")]
+	if !ok {
+		c.serveError(http.StatusBadRequest, "value must be like or dislike")
+		return
+	}
+	old := c.ownVote(userID)
+	if old == nil {
+		return
+	}
+
+	lock := voteLock(userID, old.ImageID)
+	lock.Lock()
+	defer lock.Unlock()
+
+	// Read the vote again now that we hold the lock; a concurrent change
+	// or undo may have replaced or removed it in the meantime
+	if old = c.ownVote(userID); old == nil {
+		return
+	}
+	if old.Value == value {
+		c.Data["json"] = map[string]interface{}{"id": old.ID, "image_id": old.ImageID, "value": old.Value}
+		c.ServeJSON()
+		return
+	}
+
+	// Carry on if the client goes away; a half-done change is worse
+	ctx := context.WithoutCancel(c.Ctx.Request.Context())
+	api := c.client()
+	created, err := api.CreateVote(ctx, catapi.NewVote{ImageID: old.ImageID, SubID: userID, Value: value})
+	if err != nil {
+		fmt.Println("Failed to change vote:", err)
+		c.serveError(upstreamStatus(err), "Failed to change vote")
+		return
+	}
+	if err := api.DeleteVote(ctx, old.ID); err != nil {
+		if err := api.DeleteVote(ctx, created.ID); err != nil {
+			fmt.Printf("Failed to take back vote %d: %v\n", created.ID, err)
+		}
+		// Removed behind our back, e.g. upstream; don't leave a second vote
+		if catapi.IsNotFound(err) {
+			c.serveError(http.StatusNotFound, "Vote not found")
+			return
+		}
+		fmt.Println("Failed to change vote:", err)
+		c.serveError(upstreamStatus(err), "Failed to change vote")
+		return
+	}
+
+	store := localVotes(c.Votes)
+	forgetVote(store, old.ID)
+	recordVote(store, models.Vote{
+		ID: created.ID, ImageID: old.ImageID, SubID: userID, Value: value, CreatedAt: time.Now().UTC(),
+	})
+
+	c.Data["json"] = map[string]interface{}{
+		"id":       created.ID,
+		"image_id": old.ImageID,
+		"value":    value,
+		"replaced": old.ID,
+	}
+	c.ServeJSON()
+}
+
+func (c *VotesController) serveError(status int, msg string) {
+	c.Ctx.Output.SetStatus(status)
+	c.Data["json"] = errorBody(c.Ctx, msg)
+	c.ServeJSON()
+}
+
+// imageVotes reads query.SubID's history in query.Order, up to
+// maxImageVoteScan votes, and returns the votes on imageID. The upstream
+// cannot filter by image itself.
+func imageVotes(ctx context.Context, api *catapi.Client, query catapi.VoteQuery, imageID string) ([]catapi.Vote, error) {
+	query.Limit = maxVotesLimit
+	var matches []catapi.Vote
+	for query.Page = 0; query.Page*maxVotesLimit < maxImageVoteScan; query.Page++ {
+		votes, page, err := api.ListVotes(ctx, query)
+		if err != nil {
+			return nil, err
+		}
+		for _, v := range votes {
+			if v.ImageID == imageID {
+				matches = append(matches, v)
+			}
+		}
+		if len(votes) < maxVotesLimit || (page.Count > 0 && (query.Page+1)*maxVotesLimit >= page.Count) {
+			break
+		}
+	}
+	return matches, nil
+}
+
+// joinVoteImages looks up the images of votes the upstream returned
+// without one. Images that cannot be found are left empty.
+func joinVoteImages(ctx context.Context, api *catapi.Client, votes []catapi.Vote) {
+	var missing []string
+	for _, v := range votes {
+		if v.Image.URL == "" && v.ImageID != "" {
+			missing = append(missing, v.ImageID)
+		}
+	}
+	if len(missing) == 0 {
+		return
+	}
+
+	images := lookupImages(ctx, api, missing)
+	for i, v := range votes {
+		if image, ok := images[v.ImageID]; ok && v.Image.URL == "" {
+			votes[i].Image = image
+		}
+	}
+}
+
+// lookupImages fetches the images with ids, a few at a time, and returns
+// those that were found by ID. Failures are logged and left out.
+func lookupImages(ctx context.Context, api *catapi.Client, ids []string) map[string]catapi.Image {
+	images := map[string]catapi.Image{}
+	seen := map[string]bool{}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, imageLookups)
+	for _, id := range ids {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			slots <- struct{}{}
+			defer func() { <-slots }()
+
+			image, err := api.GetImage(ctx, id)
+			if err != nil {
+				fmt.Println("Failed to fetch image:", err)
+				return
+			}
+			mu.Lock()
+			images[id] = *image
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+	return images
+}
@@ -1,212 +1,229 @@
 package controllers
 
 import (
-	"bytes"
-	"encoding/json"
+	"context"
+	"errors"
 	"fmt"
-	"io"
 	"net/http"
-	"strings"
+	"time"
 
 	"github.com/beego/beego/v2/core/config"
 	"github.com/beego/beego/v2/server/web"
+
+	"myproject/catapi"
+	"myproject/models"
 )
 
 type VotingController struct {
 	web.Controller
 	APIKey string
+	API    *catapi.Client
+	Votes  *models.VoteStore
+	Pool   *catapi.ImagePool
 }
 
-type VotingCatImage struct {
-	ID  string `json:"id"`
-	URL string `json:"url"`
-}
+type VotingCatImage = catapi.Image
 
-// Channels for fetching random cat images and handling favorite actions
-var fetchImageChan = make(chan string)
-var favoriteActionChan = make(chan string)
+// errNoImage is returned when the upstream answers with an empty list.
+var errNoImage = errors.New("no cat image returned")
+
+// imageResult carries the outcome of a random image fetch back to the
+// request that started it.
+type imageResult struct {
+	image VotingCatImage
+	err   error
+}
 
 // Initialize the controller with the API key
 func (c *VotingController) Prepare() {
+	// If API key is already set (e.g., in tests), use that
+	if c.APIKey != "" {
+		return
+	}
+
 	apiKey, err := config.String("api_key")
 	if err != nil {
-		c.Data["json"] = map[string]interface{}{"error": "Failed to load API key from configuration"}
+		c.Data["json"] = errorBody(c.Ctx, "Failed to load API key from configuration")
 		c.ServeJSON()
 		return
 	}
 	c.APIKey = apiKey // Store the API key in the controller's field
 }
 
-// Fetch a random cat image concurrently
-func fetchRandomCatImage(apiKey string) {
-	url := "https://api.thecatapi.com/v1/images/search"
-
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		fetchImageChan <- ""
-		fmt.Println("Failed to create request:", err)
-		return
-	}
-	req.Header.Set("x-api-key", apiKey)
-
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		fetchImageChan <- ""
-		fmt.Println("Failed to fetch cat image:", err)
-		return
+// client returns the injected Cat API client or the shared one for c.APIKey
+func (c *VotingController) client() *catapi.Client {
+	if c.API == nil {
+		c.API = catAPIClient(c.APIKey)
 	}
-	defer resp.Body.Close()
+	return c.API
+}
 
-	var images []VotingCatImage
-	if err := json.NewDecoder(resp.Body).Decode(&images); err != nil {
-		fetchImageChan <- ""
-		fmt.Println("Failed to decode cat image response:", err)
-		return
+// pool returns the injected image pool or the shared one for the client
+func (c *VotingController) pool() *catapi.ImagePool {
+	if c.Pool == nil {
+		c.Pool = imagePool(c.client())
 	}
+	return c.Pool
+}
 
-	if len(images) > 0 {
-		fetchImageChan <- images[0].URL + "|" + images[0].ID // Send URL and ID as a combined string
-	} else {
-		fetchImageChan <- ""
+// randomCatImage takes a random cat image from pool, or fetches one with
+// fetchRandomCatImage while the pool is empty.
+func randomCatImage(ctx context.Context, pool *catapi.ImagePool, api *catapi.Client) <-chan imageResult {
+	if image, ok := pool.Take(); ok {
+		result := make(chan imageResult, 1)
+		result <- imageResult{image: image}
+		return result
 	}
+	return fetchRandomCatImage(ctx, api)
 }
 
-// Get method to fetch a random cat image and return it as JSON
-func (c *VotingController) Get() {
+// Fetch a random cat image concurrently. The returned channel is owned by
+// the caller and buffered, so the goroutine finishes even if nobody reads.
+func fetchRandomCatImage(ctx context.Context, api *catapi.Client) <-chan imageResult {
+	result := make(chan imageResult, 1)
+	go func() {
+		images, err := api.SearchImages(ctx, catapi.ImageSearch{})
+		switch {
+		case err != nil:
+			result <- imageResult{err: err}
+		case len(images) == 0:
+			result <- imageResult{err: errNoImage}
+		default:
+			result <- imageResult{image: images[0]}
+		}
+	}()
+	return result
+}
 
-	go fetchRandomCatImage(c.APIKey)
+// voteValues are the upstream vote values of the voting actions
+var voteValues = map[string]int{"like": 1, "dislike": -1}
 
-	imageData := <-fetchImageChan // Wait for the result from the channel
-	if imageData == "" {
-		c.Data["json"] = map[string]interface{}{"error": "Failed to fetch cat image"}
-	} else {
-		dataParts := strings.Split(imageData, "|") // Split URL and ID
-		imageURL, imageID := dataParts[0], dataParts[1]
+// actionResult carries the outcome of sendAction. id is the ID of the
+// created vote or favourite.
+type actionResult struct {
+	id  int
+	err error
+}
 
-		c.Data["json"] = map[string]interface{}{
-			"image_url": imageURL,
-			"image_id":  imageID,
+// sendAction submits a like, dislike or favourite for imageID concurrently
+// and reports its outcome on the returned buffered channel.
+func sendAction(ctx context.Context, api *catapi.Client, action, imageID, userID string) <-chan actionResult {
+	done := make(chan actionResult, 1)
+	go func() {
+		var created *catapi.CreateResult
+		var err error
+		if action == "favorite" {
+			created, err = api.CreateFavourite(ctx, imageID, userID)
+		} else {
+			created, err = api.CreateVote(ctx, catapi.NewVote{ImageID: imageID, SubID: userID, Value: voteValues[action]})
+		}
+		if err != nil {
+			done <- actionResult{err: err}
+			return
 		}
+		done <- actionResult{id: created.ID}
+	}()
+	return done
+}
+
+// awaitImage waits for a fetch started by fetchRandomCatImage, giving up
+// when the client goes away.
+func awaitImage(ctx context.Context, result <-chan imageResult) (VotingCatImage, error) {
+	select {
+	case res := <-result:
+		return res.image, res.err
+	case <-ctx.Done():
+		return VotingCatImage{}, ctx.Err()
 	}
+}
 
+// serveImage writes image as the voting response, or the error message
+// if the fetch failed. Fields in extra are added to either.
+func (c *VotingController) serveImage(image VotingCatImage, err error, extra map[string]interface{}) {
+	var response map[string]interface{}
+	if err != nil {
+		fmt.Println("Failed to fetch cat image:", err)
+		response = errorBody(c.Ctx, "Failed to fetch cat image")
+	} else {
+		response = map[string]interface{}{
+			"image_url": image.URL,
+			"image_id":  image.ID,
+		}
+	}
+	for k, v := range extra {
+		response[k] = v
+	}
+	c.Data["json"] = response
 	c.ServeJSON()
 }
 
-// Post method to handle like, dislike, and saving to favorites
+// Get method to serve a random cat image as JSON, from the image pool
+// when it has one ready
+func (c *VotingController) Get() {
+	ctx := c.Ctx.Request.Context()
+
+	image, err := awaitImage(ctx, randomCatImage(ctx, c.pool(), c.client()))
+	if ctx.Err() != nil {
+		return // client disconnected
+	}
+	c.serveImage(image, err, nil)
+}
+
+// Post method to handle like, dislike, and saving to favorites. The action
+// and the fetch of the next image run concurrently; the next image is only
+// returned once the action has succeeded.
 func (c *VotingController) Post() {
+	userID := requireUser(&c.Controller)
+	if userID == "" {
+		return
+	}
+
 	action := c.GetString("action")
 	imageID := c.GetString("image_id")
-	userID := "user-123" // Use unique user ID here (you can replace it)
-
-	// Handle favorite action
-	if action == "favorite" {
-		go func() {
-			favoriteBody := map[string]string{
-				"image_id": imageID,
-				"sub_id":   userID,
-			}
 
-			bodyBytes, err := json.Marshal(favoriteBody)
-			if err != nil {
-				fmt.Println("Failed to marshal favorite request body:", err)
-				favoriteActionChan <- "error"
-				return
-			}
+	if action != "like" && action != "dislike" && action != "favorite" {
+		c.Ctx.Output.SetStatus(http.StatusBadRequest)
+		c.Data["json"] = errorBody(c.Ctx, "Unknown action")
+		c.ServeJSON()
+		return
+	}
 
-			url := "https://api.thecatapi.com/v1/favourites"
-			req, err := http.NewRequest("POST", url, bytes.NewBuffer(bodyBytes))
-			if err != nil {
-				fmt.Println("Failed to create favorite request:", err)
-				favoriteActionChan <- "error"
-				return
-			}
-			req.Header.Set("Content-Type", "application/json")
-			req.Header.Set("x-api-key", c.APIKey)
-
-			client := &http.Client{}
-			resp, err := client.Do(req)
-			if err != nil {
-				fmt.Println("Failed to make favorite request:", err)
-				favoriteActionChan <- "error"
-				return
-			}
-			defer resp.Body.Close()
+	ctx := c.Ctx.Request.Context()
+	api := c.client()
 
-			body, _ := io.ReadAll(resp.Body)
-			fmt.Println("Response Status:", resp.Status)
-			fmt.Println("Response Body:", string(body))
+	actionDone := sendAction(ctx, api, action, imageID, userID)
+	nextImage := randomCatImage(ctx, c.pool(), api)
 
-			if resp.StatusCode == http.StatusOK || resp.StatusCode == http.StatusCreated {
-				favoriteActionChan <- "done"
-			} else {
-				favoriteActionChan <- "error"
+	var recorded map[string]interface{}
+	select {
+	case res := <-actionDone:
+		if err := res.err; err != nil {
+			fmt.Printf("Failed to %s image %s: %v\n", action, imageID, err)
+			msg := "Failed to record vote"
+			if action == "favorite" {
+				msg = "Failed to favorite the image"
 			}
-		}()
-	}
-
-	// Wait for favorite action to complete (if applicable)
-	if action == "favorite" {
-		status := <-favoriteActionChan
-		if status == "error" {
-			c.Data["json"] = map[string]interface{}{"error": "Failed to favorite the image"}
+			c.Data["json"] = errorBody(c.Ctx, msg)
 			c.ServeJSON()
 			return
 		}
-	}
-
-	// Handle voting (like or dislike)
-	if action == "like" || action == "dislike" {
-		voteValue := 0
-		if action == "like" {
-			voteValue = 1
-		} else if action == "dislike" {
-			voteValue = -1
-		}
-
-		// Send vote to The Cat API
-		go func() {
-			voteBody := map[string]interface{}{
-				"image_id": imageID,
-				"sub_id":   userID,
-				"value":    voteValue,
-			}
-
-			bodyBytes, err := json.Marshal(voteBody)
-			if err != nil {
-				fmt.Println("Failed to marshal vote request body:", err)
-				return
-			}
-
-			voteURL := "https://api.thecatapi.com/v1/votes"
-			req, err := http.NewRequest("POST", voteURL, bytes.NewBuffer(bodyBytes))
-			if err != nil {
-				fmt.Println("Failed to create vote request:", err)
-				return
-			}
-			req.Header.Set("Content-Type", "application/json")
-			req.Header.Set("x-api-key", c.APIKey)
-
-			client := &http.Client{}
-			resp, err := client.Do(req)
-			if err != nil {
-				fmt.Println("Failed to send vote:", err)
-				return
+		if action != "favorite" {
+			recordVote(localVotes(c.Votes), models.Vote{
+				ID: res.id, ImageID: imageID, SubID: userID, Value: voteValues[action], CreatedAt: time.Now().UTC(),
+			})
+			// Let the UI offer to undo or change the vote for a while
+			recorded = map[string]interface{}{
+				"vote_id":      res.id,
+				"undo_seconds": int(voteUndoWindow().Seconds()),
 			}
-			defer resp.Body.Close()
-
-			body, _ := io.ReadAll(resp.Body)
-			fmt.Println("Vote Response Status:", resp.Status)
-			fmt.Println("Vote Response Body:", string(body))
-		}()
+		}
+	case <-ctx.Done():
+		return // client disconnected
 	}
 
-	// Fetch a new random cat image after action (if applicable)
-	c.Get()
-
-	c.Data["json"] = map[string]interface{}{
-		"image_url": c.Data["ImageURL"],
-		"image_id":  c.Data["ImageID"],
+	image, err := awaitImage(ctx, nextImage)
+	if ctx.Err() != nil {
+		return // client disconnected
 	}
-	c.ServeJSON()
+	c.serveImage(image, err, recorded)
 }
@@ -0,0 +1,24 @@
+// Package elo implements the Elo rating system for head-to-head votes
+// between images.
+package elo
+
+import "math"
+
+// DefaultRating is the rating of an image that has never been in a duel.
+const DefaultRating = 1500.0
+
+// DefaultK is how far a single duel can move a rating.
+const DefaultK = 32.0
+
+// Expected returns the probability that an image rated a beats one rated b.
+func Expected(a, b float64) float64 {
+	return 1 / (1 + math.Pow(10, (b-a)/400))
+}
+
+// Update returns the ratings of winner and loser after the first beat the
+// second, moving each by k times how surprising the result was. The sum
+// of the two ratings is unchanged.
+func Update(winner, loser, k float64) (float64, float64) {
+	delta := k * (1 - Expected(winner, loser))
+	return winner + delta, loser - delta
+}
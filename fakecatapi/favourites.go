@@ -0,0 +1,124 @@
+package fakecatapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"time"
+)
+
+type favourite struct {
+	ID        int
+	ImageID   string
+	SubID     string
+	CreatedAt time.Time
+}
+
+func (s *Server) favouriteJSON(r *http.Request, f *favourite) map[string]interface{} {
+	return map[string]interface{}{
+		"id":         f.ID,
+		"image_id":   f.ImageID,
+		"sub_id":     f.SubID,
+		"created_at": timestamp(f.CreatedAt),
+		"image":      s.imageRef(r, f.ImageID),
+	}
+}
+
+// findFavourite returns the favourite with id. Callers must hold s.mu.
+func (s *Server) findFavourite(id int) (int, *favourite) {
+	for i, f := range s.favourites {
+		if f.ID == id {
+			return i, f
+		}
+	}
+	return -1, nil
+}
+
+// listFavourites filters by sub_id and pages in ASC (default) or DESC
+// creation order.
+func (s *Server) listFavourites(w http.ResponseWriter, r *http.Request) {
+	subID := r.URL.Query().Get("sub_id")
+	p := parsePaging(r, maxLimit)
+
+	s.mu.Lock()
+	var matches []*favourite
+	for _, f := range s.favourites {
+		if subID == "" || f.SubID == subID {
+			matches = append(matches, f)
+		}
+	}
+	s.mu.Unlock()
+
+	if p.Order == "DESC" {
+		slices.Reverse(matches)
+	}
+	start, end := p.window(w, len(matches))
+
+	out := make([]map[string]interface{}, 0, end-start)
+	for _, f := range matches[start:end] {
+		out = append(out, s.favouriteJSON(r, f))
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+func (s *Server) createFavourite(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		ImageID string `json:"image_id"`
+		SubID   string `json:"sub_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "INVALID_BODY", http.StatusBadRequest)
+		return
+	}
+	if body.ImageID == "" {
+		http.Error(w, `"image_id" is required`, http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, f := range s.favourites {
+		if f.ImageID == body.ImageID && f.SubID == body.SubID {
+			http.Error(w, "DUPLICATE_FAVOURITE - favourites are unique for account + image_id + sub_id", http.StatusBadRequest)
+			return
+		}
+	}
+
+	s.nextID++
+	f := &favourite{ID: s.nextID, ImageID: body.ImageID, SubID: body.SubID, CreatedAt: s.now()}
+	s.favourites = append(s.favourites, f)
+	writeJSON(w, http.StatusOK, map[string]interface{}{"message": "SUCCESS", "id": f.ID})
+}
+
+func (s *Server) getFavourite(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+
+	s.mu.Lock()
+	_, f := s.findFavourite(id)
+	s.mu.Unlock()
+	if f == nil {
+		http.Error(w, "NOT_FOUND", http.StatusNotFound)
+		return
+	}
+	writeJSON(w, http.StatusOK, s.favouriteJSON(r, f))
+}
+
+func (s *Server) deleteFavourite(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i, f := s.findFavourite(id)
+	if f == nil {
+		http.Error(w, "NOT_FOUND", http.StatusNotFound)
+		return
+	}
+	s.favourites = append(s.favourites[:i], s.favourites[i+1:]...)
+	writeJSON(w, http.StatusOK, map[string]interface{}{"message": "SUCCESS"})
+}
@@ -0,0 +1,166 @@
+package fakecatapi
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"net/http"
+	"sort"
+)
+
+//go:embed fixtures/breeds.json
+var breedsJSON []byte
+
+// imagesPerBreed and miscImages size the seeded image catalogue.
+const (
+	imagesPerBreed = 12
+	miscImages     = 24
+)
+
+// fixtureImage is a seeded image. Its pixels are rendered on demand by
+// renderImage so the fake works without any image assets.
+type fixtureImage struct {
+	ID      string
+	BreedID string
+	Ext     string
+	Width   int
+	Height  int
+}
+
+func loadBreeds() []map[string]interface{} {
+	var breeds []map[string]interface{}
+	if err := json.Unmarshal(breedsJSON, &breeds); err != nil {
+		panic(fmt.Sprintf("fakecatapi: invalid breeds fixture: %v", err))
+	}
+	return breeds
+}
+
+// seedImages creates imagesPerBreed images for every breed plus a set of
+// images without a breed, mixing extensions and sizes deterministically.
+func seedImages(breeds []map[string]interface{}) []fixtureImage {
+	sizes := [][2]int{{400, 300}, {500, 375}, {600, 400}, {480, 480}}
+	newImage := func(id, breedID string, n int) fixtureImage {
+		ext := "jpg"
+		switch {
+		case n%5 == 0:
+			ext = "gif"
+		case n%3 == 0:
+			ext = "png"
+		}
+		size := sizes[n%len(sizes)]
+		return fixtureImage{ID: id, BreedID: breedID, Ext: ext, Width: size[0], Height: size[1]}
+	}
+
+	var images []fixtureImage
+	for _, breed := range breeds {
+		breedID, _ := breed["id"].(string)
+		for n := 1; n <= imagesPerBreed; n++ {
+			images = append(images, newImage(fmt.Sprintf("%s-%d", breedID, n), breedID, n))
+		}
+	}
+	for n := 1; n <= miscImages; n++ {
+		images = append(images, newImage(fmt.Sprintf("misc-%d", n), "", n))
+	}
+	sort.Slice(images, func(i, j int) bool { return images[i].ID < images[j].ID })
+	return images
+}
+
+func (img fixtureImage) url(r *http.Request) string {
+	return fmt.Sprintf("%s/images/%s.%s", baseURL(r), img.ID, img.Ext)
+}
+
+func (s *Server) findImage(id string) (fixtureImage, bool) {
+	i := sort.Search(len(s.images), func(i int) bool { return s.images[i].ID >= id })
+	if i < len(s.images) && s.images[i].ID == id {
+		return s.images[i], true
+	}
+	return fixtureImage{}, false
+}
+
+func (s *Server) findBreed(id string) (map[string]interface{}, bool) {
+	for _, breed := range s.breeds {
+		if breed["id"] == id {
+			return breed, true
+		}
+	}
+	return nil, false
+}
+
+// breedJSON returns a copy of breed with the reference image expanded,
+// as the upstream does.
+func (s *Server) breedJSON(r *http.Request, breed map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(breed)+1)
+	for k, v := range breed {
+		out[k] = v
+	}
+	if refID, ok := breed["reference_image_id"].(string); ok {
+		if img, ok := s.findImage(refID); ok {
+			out["image"] = map[string]interface{}{
+				"id":     img.ID,
+				"url":    img.url(r),
+				"width":  img.Width,
+				"height": img.Height,
+			}
+		}
+	}
+	return out
+}
+
+func (s *Server) imageJSON(r *http.Request, img fixtureImage) map[string]interface{} {
+	breeds := []interface{}{}
+	if breed, ok := s.findBreed(img.BreedID); ok {
+		breeds = append(breeds, breed)
+	}
+	return map[string]interface{}{
+		"id":     img.ID,
+		"url":    img.url(r),
+		"width":  img.Width,
+		"height": img.Height,
+		"breeds": breeds,
+	}
+}
+
+// imageRef is the short image object embedded in favourites and votes.
+func (s *Server) imageRef(r *http.Request, imageID string) map[string]interface{} {
+	img, ok := s.findImage(imageID)
+	if !ok {
+		return map[string]interface{}{}
+	}
+	return map[string]interface{}{"id": img.ID, "url": img.url(r)}
+}
+
+// listBreeds serves the breed list with an ETag and honours
+// If-None-Match, so clients can revalidate cached copies.
+func (s *Server) listBreeds(w http.ResponseWriter, r *http.Request) {
+	out := make([]map[string]interface{}, 0, len(s.breeds))
+	for _, breed := range s.breeds {
+		out = append(out, s.breedJSON(r, breed))
+	}
+	body, err := json.Marshal(out)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	h := fnv.New64a()
+	h.Write(body)
+	etag := fmt.Sprintf(`W/"%x"`, h.Sum64())
+	w.Header().Set("ETag", etag)
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Write(body)
+}
+
+func (s *Server) getBreed(w http.ResponseWriter, r *http.Request) {
+	breed, ok := s.findBreed(r.PathValue("id"))
+	if !ok {
+		http.Error(w, "NOT_FOUND", http.StatusNotFound)
+		return
+	}
+	writeJSON(w, http.StatusOK, s.breedJSON(r, breed))
+}
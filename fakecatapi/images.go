@@ -0,0 +1,108 @@
+package fakecatapi
+
+import (
+	"hash/fnv"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+	"path"
+	"slices"
+	"strings"
+)
+
+// searchImages implements /images/search with breed_ids, mime_types,
+// limit, page and order (RAND, ASC or DESC).
+func (s *Server) searchImages(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	breedIDs := splitList(q.Get("breed_ids"))
+	mimeTypes := splitList(q.Get("mime_types"))
+
+	var matches []fixtureImage
+	for _, img := range s.images {
+		if len(breedIDs) > 0 && !breedIDs[img.BreedID] {
+			continue
+		}
+		if len(mimeTypes) > 0 && !mimeTypes[img.Ext] && !(img.Ext == "jpg" && mimeTypes["jpeg"]) {
+			continue
+		}
+		matches = append(matches, img)
+	}
+
+	p := parsePaging(r, 1)
+	var selected []fixtureImage
+	switch p.Order {
+	case "ASC", "DESC":
+		if p.Order == "DESC" {
+			slices.Reverse(matches)
+		}
+		start, end := p.window(w, len(matches))
+		selected = matches[start:end]
+	default:
+		s.mu.Lock()
+		s.rand.Shuffle(len(matches), func(i, j int) { matches[i], matches[j] = matches[j], matches[i] })
+		s.mu.Unlock()
+		if len(matches) > p.Limit {
+			matches = matches[:p.Limit]
+		}
+		selected = matches
+	}
+
+	out := make([]map[string]interface{}, 0, len(selected))
+	for _, img := range selected {
+		out = append(out, s.imageJSON(r, img))
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+func (s *Server) getImage(w http.ResponseWriter, r *http.Request) {
+	img, ok := s.findImage(r.PathValue("id"))
+	if !ok {
+		http.Error(w, "NOT_FOUND", http.StatusNotFound)
+		return
+	}
+	writeJSON(w, http.StatusOK, s.imageJSON(r, img))
+}
+
+// renderImage serves a flat placeholder picture for a seeded image, in a
+// colour derived from its ID and the format implied by its extension.
+func (s *Server) renderImage(w http.ResponseWriter, r *http.Request) {
+	file := r.PathValue("file")
+	ext := strings.TrimPrefix(path.Ext(file), ".")
+	img, ok := s.findImage(strings.TrimSuffix(file, path.Ext(file)))
+	if !ok || img.Ext != ext {
+		http.NotFound(w, r)
+		return
+	}
+
+	h := fnv.New32a()
+	h.Write([]byte(img.ID))
+	sum := h.Sum32()
+	fill := color.RGBA{R: uint8(sum >> 16), G: uint8(sum >> 8), B: uint8(sum), A: 255}
+
+	canvas := image.NewPaletted(image.Rect(0, 0, img.Width, img.Height), color.Palette{fill})
+	switch ext {
+	case "png":
+		w.Header().Set("Content-Type", "image/png")
+		_ = png.Encode(w, canvas)
+	case "gif":
+		w.Header().Set("Content-Type", "image/gif")
+		_ = gif.Encode(w, canvas, nil)
+	default:
+		w.Header().Set("Content-Type", "image/jpeg")
+		_ = jpeg.Encode(w, canvas, nil)
+	}
+}
+
+// splitList parses a comma separated query value into a set.
+func splitList(s string) map[string]bool {
+	set := map[string]bool{}
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			set[item] = true
+		}
+	}
+	return set
+}
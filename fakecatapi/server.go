@@ -0,0 +1,184 @@
+// Package fakecatapi is an in-memory stand-in for The Cat API. It serves
+// the subset of /v1 the app uses from seeded fixtures so that development
+// and tests can run without network access.
+package fakecatapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxLimit mirrors the upstream cap on page sizes.
+const maxLimit = 100
+
+// Server is an http.Handler emulating The Cat API. It is safe for
+// concurrent use.
+type Server struct {
+	mux    *http.ServeMux
+	breeds []map[string]interface{}
+	images []fixtureImage
+	now    func() time.Time
+
+	mu         sync.Mutex
+	rand       *rand.Rand
+	nextID     int
+	favourites []*favourite
+	votes      []*vote
+}
+
+// Option configures a Server.
+type Option func(*Server)
+
+// WithSeed makes random image selection deterministic.
+func WithSeed(seed int64) Option {
+	return func(s *Server) {
+		s.rand = rand.New(rand.NewSource(seed))
+	}
+}
+
+// WithClock overrides the clock used for created_at timestamps.
+func WithClock(now func() time.Time) Option {
+	return func(s *Server) {
+		s.now = now
+	}
+}
+
+// New returns a Server seeded with the bundled fixtures.
+func New(opts ...Option) *Server {
+	s := &Server{
+		mux:    http.NewServeMux(),
+		breeds: loadBreeds(),
+		now:    time.Now,
+		rand:   rand.New(rand.NewSource(time.Now().UnixNano())),
+		nextID: 1000,
+	}
+	s.images = seedImages(s.breeds)
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	s.mux.HandleFunc("GET /v1/breeds", s.listBreeds)
+	s.mux.HandleFunc("GET /v1/breeds/{id}", s.getBreed)
+	s.mux.HandleFunc("GET /v1/images/search", s.searchImages)
+	s.mux.HandleFunc("GET /v1/images/{id}", s.getImage)
+	s.mux.HandleFunc("GET /v1/favourites", s.requireKey(s.listFavourites))
+	s.mux.HandleFunc("POST /v1/favourites", s.requireKey(s.createFavourite))
+	s.mux.HandleFunc("GET /v1/favourites/{id}", s.requireKey(s.getFavourite))
+	s.mux.HandleFunc("DELETE /v1/favourites/{id}", s.requireKey(s.deleteFavourite))
+	s.mux.HandleFunc("GET /v1/votes", s.requireKey(s.listVotes))
+	s.mux.HandleFunc("POST /v1/votes", s.requireKey(s.createVote))
+	s.mux.HandleFunc("GET /v1/votes/{id}", s.requireKey(s.getVote))
+	s.mux.HandleFunc("DELETE /v1/votes/{id}", s.requireKey(s.deleteVote))
+	s.mux.HandleFunc("GET /images/{file}", s.renderImage)
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// requireKey rejects requests without an x-api-key header, as the
+// upstream does for account-scoped resources.
+func (s *Server) requireKey(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("x-api-key") == "" {
+			http.Error(w, "AUTHENTICATION_ERROR - you need to send your API Key as the 'x-api-key' header", http.StatusUnauthorized)
+			return
+		}
+		next(w, r)
+	}
+}
+
+// writeJSON encodes v with the given status.
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// baseURL is the scheme and host the request was addressed to, used to
+// build image URLs that point back at this server.
+func baseURL(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	if proto := r.Header.Get("X-Forwarded-Proto"); proto != "" {
+		scheme = proto
+	}
+	return scheme + "://" + r.Host
+}
+
+// paging holds the page/limit/order query parameters shared by the list
+// endpoints.
+type paging struct {
+	Page  int
+	Limit int
+	Order string
+}
+
+func parsePaging(r *http.Request, defaultLimit int) paging {
+	q := r.URL.Query()
+	p := paging{
+		Page:  atoiDefault(q.Get("page"), 0),
+		Limit: atoiDefault(q.Get("limit"), defaultLimit),
+		Order: strings.ToUpper(q.Get("order")),
+	}
+	if p.Page < 0 {
+		p.Page = 0
+	}
+	if p.Limit < 1 {
+		p.Limit = 1
+	}
+	if p.Limit > maxLimit {
+		p.Limit = maxLimit
+	}
+	return p
+}
+
+// window returns the [start, end) slice bounds of the page over total
+// items and sets the upstream pagination headers.
+func (p paging) window(w http.ResponseWriter, total int) (int, int) {
+	w.Header().Set("Pagination-Count", strconv.Itoa(total))
+	w.Header().Set("Pagination-Page", strconv.Itoa(p.Page))
+	w.Header().Set("Pagination-Limit", strconv.Itoa(p.Limit))
+
+	start := p.Page * p.Limit
+	if start > total {
+		start = total
+	}
+	end := start + p.Limit
+	if end > total {
+		end = total
+	}
+	return start, end
+}
+
+func atoiDefault(s string, def int) int {
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return def
+	}
+	return n
+}
+
+// pathID parses the numeric {id} path segment, writing a 400 on failure.
+func pathID(w http.ResponseWriter, r *http.Request) (int, bool) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("INVALID_ID - %q is not a valid id", r.PathValue("id")), http.StatusBadRequest)
+		return 0, false
+	}
+	return id, true
+}
+
+// timestamp formats t the way the upstream does.
+func timestamp(t time.Time) string {
+	return t.UTC().Format("2006-01-02T15:04:05.000Z")
+}
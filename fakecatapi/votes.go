@@ -0,0 +1,137 @@
+package fakecatapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"time"
+)
+
+// countryCode is reported for every vote, standing in for the upstream's
+// geo lookup.
+const countryCode = "BD"
+
+type vote struct {
+	ID        int
+	ImageID   string
+	SubID     string
+	Value     int
+	CreatedAt time.Time
+}
+
+func (s *Server) voteJSON(r *http.Request, v *vote) map[string]interface{} {
+	return map[string]interface{}{
+		"id":           v.ID,
+		"image_id":     v.ImageID,
+		"sub_id":       v.SubID,
+		"value":        v.Value,
+		"country_code": countryCode,
+		"created_at":   timestamp(v.CreatedAt),
+		"image":        s.imageRef(r, v.ImageID),
+	}
+}
+
+// findVote returns the vote with id. Callers must hold s.mu.
+func (s *Server) findVote(id int) (int, *vote) {
+	for i, v := range s.votes {
+		if v.ID == id {
+			return i, v
+		}
+	}
+	return -1, nil
+}
+
+// listVotes filters by sub_id and pages in ASC (default) or DESC
+// creation order.
+func (s *Server) listVotes(w http.ResponseWriter, r *http.Request) {
+	subID := r.URL.Query().Get("sub_id")
+	p := parsePaging(r, maxLimit)
+
+	s.mu.Lock()
+	var matches []*vote
+	for _, v := range s.votes {
+		if subID == "" || v.SubID == subID {
+			matches = append(matches, v)
+		}
+	}
+	s.mu.Unlock()
+
+	if p.Order == "DESC" {
+		slices.Reverse(matches)
+	}
+	start, end := p.window(w, len(matches))
+
+	out := make([]map[string]interface{}, 0, end-start)
+	for _, v := range matches[start:end] {
+		out = append(out, s.voteJSON(r, v))
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+func (s *Server) createVote(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		ImageID string `json:"image_id"`
+		SubID   string `json:"sub_id"`
+		Value   *int   `json:"value"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "INVALID_BODY", http.StatusBadRequest)
+		return
+	}
+	if body.ImageID == "" {
+		http.Error(w, `"image_id" is required`, http.StatusBadRequest)
+		return
+	}
+	if body.Value == nil {
+		http.Error(w, `"value" is required`, http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	s.nextID++
+	v := &vote{ID: s.nextID, ImageID: body.ImageID, SubID: body.SubID, Value: *body.Value, CreatedAt: s.now()}
+	s.votes = append(s.votes, v)
+	s.mu.Unlock()
+
+	writeJSON(w, http.StatusCreated, map[string]interface{}{
+		"message":      "SUCCESS",
+		"id":           v.ID,
+		"image_id":     v.ImageID,
+		"sub_id":       v.SubID,
+		"value":        v.Value,
+		"country_code": countryCode,
+	})
+}
+
+func (s *Server) getVote(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+
+	s.mu.Lock()
+	_, v := s.findVote(id)
+	s.mu.Unlock()
+	if v == nil {
+		http.Error(w, "NOT_FOUND", http.StatusNotFound)
+		return
+	}
+	writeJSON(w, http.StatusOK, s.voteJSON(r, v))
+}
+
+func (s *Server) deleteVote(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i, v := s.findVote(id)
+	if v == nil {
+		http.Error(w, "NOT_FOUND", http.StatusNotFound)
+		return
+	}
+	s.votes = append(s.votes[:i], s.votes[i+1:]...)
+	writeJSON(w, http.StatusOK, map[string]interface{}{"message": "SUCCESS"})
+}
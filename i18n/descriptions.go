@@ -0,0 +1,158 @@
+package i18n
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"sync"
+	"time"
+
+	"myproject/catapi"
+)
+
+// Translator translates English text into lang. It is the pluggable source
+// of breed description translations, such as a machine translation service.
+type Translator interface {
+	Translate(ctx context.Context, text, lang string) (string, error)
+}
+
+// Overrides are hand-written breed descriptions by language and breed ID,
+// e.g. {"de": {"abys": "Die Abessinierkatze ..."}}.
+type Overrides map[string]map[string]string
+
+// LoadOverrides reads overrides from a JSON file. A missing file means
+// there are none.
+func LoadOverrides(path string) (Overrides, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return Overrides{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var overrides Overrides
+	if err := json.Unmarshal(data, &overrides); err != nil {
+		return nil, fmt.Errorf("i18n: parse %s: %w", path, err)
+	}
+	return overrides, nil
+}
+
+// descriptionKey identifies a translated description. The English text is
+// part of the key so an updated description is translated again.
+type descriptionKey struct {
+	lang, breedID, text string
+}
+
+// Limits on machine translation done by Descriptions
+const (
+	// maxTranslations is how many translations run at once
+	maxTranslations = 4
+	// translateTimeout bounds a single translation
+	translateTimeout = 30 * time.Second
+	// defaultRetryAfter is how long a failed translation is not retried
+	defaultRetryAfter = 5 * time.Minute
+)
+
+// DescriptionOption configures Descriptions.
+type DescriptionOption func(*Descriptions)
+
+// WithRetryAfter sets how long a description whose translation failed is
+// served in English before it is translated again.
+func WithRetryAfter(d time.Duration) DescriptionOption {
+	return func(ds *Descriptions) { ds.retryAfter = d }
+}
+
+// Descriptions localizes breed descriptions. An override wins; otherwise
+// the source translates the English description in the background and the
+// result is kept. Until a translation is ready, without either, or for a
+// while after the source fails, the English description is used, so
+// callers never wait on the source. It is safe for concurrent use.
+type Descriptions struct {
+	overrides  Overrides
+	source     Translator
+	retryAfter time.Duration
+	slots      chan struct{}
+	inFlight   sync.WaitGroup
+
+	mu         sync.Mutex
+	translated map[descriptionKey]string
+	pending    map[descriptionKey]bool
+	failed     map[descriptionKey]time.Time
+}
+
+// NewDescriptions returns Descriptions over overrides and source, either
+// of which may be nil.
+func NewDescriptions(overrides Overrides, source Translator, opts ...DescriptionOption) *Descriptions {
+	d := &Descriptions{
+		overrides:  overrides,
+		source:     source,
+		retryAfter: defaultRetryAfter,
+		slots:      make(chan struct{}, maxTranslations),
+		translated: map[descriptionKey]string{},
+		pending:    map[descriptionKey]bool{},
+		failed:     map[descriptionKey]time.Time{},
+	}
+	for _, opt := range opts {
+		opt(d)
+	}
+	return d
+}
+
+// Describe returns breed's description in lang, starting its translation
+// if there is none yet.
+func (d *Descriptions) Describe(ctx context.Context, lang string, breed catapi.Breed) string {
+	if lang == Default || breed.Description == "" {
+		return breed.Description
+	}
+	if text, ok := d.overrides[lang][breed.ID]; ok {
+		return text
+	}
+	if d.source == nil {
+		return breed.Description
+	}
+
+	key := descriptionKey{lang, breed.ID, breed.Description}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if text, ok := d.translated[key]; ok {
+		return text
+	}
+	if d.pending[key] || time.Now().Before(d.failed[key]) {
+		return breed.Description
+	}
+	d.pending[key] = true
+	d.inFlight.Add(1)
+	go d.translate(key)
+	return breed.Description
+}
+
+// Wait blocks until the translations started so far have finished.
+func (d *Descriptions) Wait() {
+	d.inFlight.Wait()
+}
+
+// translate runs one translation once a slot is free and records its
+// outcome. It outlives the request that started it.
+func (d *Descriptions) translate(key descriptionKey) {
+	defer d.inFlight.Done()
+	d.slots <- struct{}{}
+	defer func() { <-d.slots }()
+
+	ctx, cancel := context.WithTimeout(context.Background(), translateTimeout)
+	defer cancel()
+	text, err := d.source.Translate(ctx, key.text, key.lang)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	delete(d.pending, key)
+	if err != nil {
+		fmt.Println("Failed to translate breed description:", err)
+		d.failed[key] = time.Now().Add(d.retryAfter)
+		return
+	}
+	delete(d.failed, key)
+	d.translated[key] = text
+}
@@ -0,0 +1,207 @@
+// Package i18n picks the language of a request and translates UI strings,
+// API error messages and breed descriptions. English is the source
+// language; German and Bengali catalogues are embedded in the binary.
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Default is the language used when a request asks for none we support.
+const Default = "en"
+
+// Supported lists the languages with a catalogue, Default first.
+var Supported = []string{"en", "de", "bn"}
+
+// Names are the languages' own names, for a language switcher.
+var Names = map[string]string{
+	"en": "English",
+	"de": "Deutsch",
+	"bn": "বাংলা",
+}
+
+//go:embed locales/*.json
+var locales embed.FS
+
+// catalogue holds one language's translations. UI strings are keyed by
+// ID; error messages by their English text, which may be a format string
+// with %s and %d verbs.
+type catalogue struct {
+	UI       map[string]string `json:"ui"`
+	Errors   map[string]string `json:"errors"`
+	patterns []errorPattern
+}
+
+// errorPattern recognises a formatted English error message so it can be
+// translated after the fact.
+type errorPattern struct {
+	re          *regexp.Regexp
+	verbs       []byte
+	translation string
+	literal     int
+}
+
+var catalogues = loadCatalogues()
+
+func loadCatalogues() map[string]*catalogue {
+	out := map[string]*catalogue{}
+	for _, lang := range Supported {
+		data, err := locales.ReadFile("locales/" + lang + ".json")
+		if err != nil {
+			panic(fmt.Sprintf("i18n: missing catalogue for %s: %v", lang, err))
+		}
+		var c catalogue
+		if err := json.Unmarshal(data, &c); err != nil {
+			panic(fmt.Sprintf("i18n: invalid catalogue for %s: %v", lang, err))
+		}
+		for msg, translation := range c.Errors {
+			if p, ok := compilePattern(msg, translation); ok {
+				c.patterns = append(c.patterns, p)
+			}
+		}
+		// Most specific first, so "limit must be between 1 and %d" wins
+		// over "%s must be between 1 and 5"
+		sort.Slice(c.patterns, func(i, j int) bool {
+			if c.patterns[i].literal != c.patterns[j].literal {
+				return c.patterns[i].literal > c.patterns[j].literal
+			}
+			return c.patterns[i].re.String() < c.patterns[j].re.String()
+		})
+		out[lang] = &c
+	}
+	return out
+}
+
+// compilePattern turns a format such as "limit must be between 1 and %d"
+// into a regexp capturing its arguments. ok is false without verbs.
+func compilePattern(format, translation string) (errorPattern, bool) {
+	var expr strings.Builder
+	p := errorPattern{translation: translation}
+	expr.WriteString("^")
+	rest := format
+	for {
+		i := strings.IndexByte(rest, '%')
+		if i < 0 || i == len(rest)-1 {
+			break
+		}
+		expr.WriteString(regexp.QuoteMeta(rest[:i]))
+		p.literal += i
+		switch verb := rest[i+1]; verb {
+		case 'd':
+			expr.WriteString(`(-?\d+)`)
+			p.verbs = append(p.verbs, verb)
+		case 's':
+			expr.WriteString(`(.+?)`)
+			p.verbs = append(p.verbs, verb)
+		default:
+			expr.WriteString(regexp.QuoteMeta(rest[i : i+2]))
+			p.literal += 2
+		}
+		rest = rest[i+2:]
+	}
+	if len(p.verbs) == 0 {
+		return p, false
+	}
+	expr.WriteString(regexp.QuoteMeta(rest))
+	expr.WriteString("$")
+	p.literal += len(rest)
+	p.re = regexp.MustCompile(expr.String())
+	return p, true
+}
+
+// Match returns the supported language for a tag such as "de-AT" or "BN".
+func Match(tag string) (string, bool) {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	if i := strings.IndexAny(tag, "-_"); i >= 0 {
+		tag = tag[:i]
+	}
+	for _, lang := range Supported {
+		if tag == lang {
+			return lang, true
+		}
+	}
+	return "", false
+}
+
+// Negotiate picks the language of a request: preferred, e.g. from a query
+// parameter or cookie, when it is supported, otherwise the best supported
+// language of an Accept-Language header, otherwise Default.
+func Negotiate(preferred, acceptLanguage string) string {
+	if lang, ok := Match(preferred); ok {
+		return lang
+	}
+	type choice struct {
+		tag string
+		q   float64
+	}
+	var choices []choice
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(part, ";")
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		if tag = strings.TrimSpace(tag); tag != "" && q > 0 {
+			choices = append(choices, choice{tag, q})
+		}
+	}
+	sort.SliceStable(choices, func(i, j int) bool { return choices[i].q > choices[j].q })
+	for _, c := range choices {
+		if lang, ok := Match(c.tag); ok {
+			return lang
+		}
+	}
+	return Default
+}
+
+// T returns the UI string with key in lang, falling back to English and
+// then to the key itself.
+func T(lang, key string) string {
+	if c, ok := catalogues[lang]; ok {
+		if s, ok := c.UI[key]; ok {
+			return s
+		}
+	}
+	if s, ok := catalogues[Default].UI[key]; ok {
+		return s
+	}
+	return key
+}
+
+// Error translates an English API error message into lang. Messages built
+// with fmt from a catalogued format are recognised and rebuilt with their
+// arguments. Unknown messages are returned unchanged.
+func Error(lang, msg string) string {
+	c, ok := catalogues[lang]
+	if !ok || lang == Default {
+		return msg
+	}
+	if s, ok := c.Errors[msg]; ok {
+		return s
+	}
+	for _, p := range c.patterns {
+		m := p.re.FindStringSubmatch(msg)
+		if m == nil {
+			continue
+		}
+		args := make([]interface{}, len(p.verbs))
+		for i, verb := range p.verbs {
+			args[i] = m[i+1]
+			if verb == 'd' {
+				args[i], _ = strconv.Atoi(m[i+1])
+			}
+		}
+		return fmt.Sprintf(p.translation, args...)
+	}
+	return msg
+}
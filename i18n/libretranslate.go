@@ -0,0 +1,66 @@
+package i18n
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// LibreTranslate is a Translator backed by a LibreTranslate server.
+type LibreTranslate struct {
+	// URL is the server's base URL, e.g. "https://libretranslate.example.com".
+	URL string
+	// APIKey is sent when the server requires one.
+	APIKey string
+	// Client defaults to an http.Client with a 10 second timeout.
+	Client *http.Client
+}
+
+var defaultTranslateClient = &http.Client{Timeout: 10 * time.Second}
+
+// Translate asks the server to translate text from English into lang.
+func (t *LibreTranslate) Translate(ctx context.Context, text, lang string) (string, error) {
+	body, err := json.Marshal(map[string]string{
+		"q":       text,
+		"source":  Default,
+		"target":  lang,
+		"format":  "text",
+		"api_key": t.APIKey,
+	})
+	if err != nil {
+		return "", err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimRight(t.URL, "/")+"/translate", bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	client := t.Client
+	if client == nil {
+		client = defaultTranslateClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("i18n: translate: unexpected status %d", resp.StatusCode)
+	}
+	var result struct {
+		TranslatedText string `json:"translatedText"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("i18n: translate: %w", err)
+	}
+	if result.TranslatedText == "" {
+		return "", fmt.Errorf("i18n: translate: empty translation")
+	}
+	return result.TranslatedText, nil
+}
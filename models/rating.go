@@ -0,0 +1,171 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"myproject/elo"
+)
+
+// Rating is an image's Elo rating from head-to-head duels.
+type Rating struct {
+	ImageID   string    `json:"image_id"`
+	Rating    float64   `json:"rating"`
+	Wins      int       `json:"wins"`
+	Losses    int       `json:"losses"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// RatingStore keeps image ratings in memory and, when it has a path,
+// persists them to a JSON file after every duel. It is safe for
+// concurrent use.
+type RatingStore struct {
+	path string
+	k    float64
+
+	mu      sync.RWMutex
+	byImage map[string]*Rating
+}
+
+// NewRatingStore loads ratings from path, to be moved by k per duel. An
+// empty path keeps ratings in memory only; a missing file starts every
+// image at elo.DefaultRating.
+func NewRatingStore(path string, k float64) (*RatingStore, error) {
+	s := &RatingStore{path: path, k: k, byImage: map[string]*Rating{}}
+	if path == "" {
+		return s, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read rating store: %w", err)
+	}
+
+	var ratings []*Rating
+	if err := json.Unmarshal(data, &ratings); err != nil {
+		return nil, fmt.Errorf("parse rating store %s: %w", path, err)
+	}
+	for _, r := range ratings {
+		s.byImage[r.ImageID] = r
+	}
+	return s, nil
+}
+
+// Get returns the rating of imageID.
+func (s *RatingStore) Get(imageID string) Rating {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if r, ok := s.byImage[imageID]; ok {
+		return *r
+	}
+	return Rating{ImageID: imageID, Rating: elo.DefaultRating}
+}
+
+// RecordDuel rates winnerID's win over loserID and returns both new
+// ratings. Both change together or, if they cannot be saved, not at all.
+func (s *RatingStore) RecordDuel(winnerID, loserID string) (Rating, Rating, error) {
+	if winnerID == loserID {
+		return Rating{}, Rating{}, fmt.Errorf("an image cannot duel itself")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	winner, loser := s.rating(winnerID), s.rating(loserID)
+	oldWinner, oldLoser := *winner, *loser
+
+	now := time.Now().UTC()
+	winner.Rating, loser.Rating = elo.Update(winner.Rating, loser.Rating, s.k)
+	winner.Wins++
+	loser.Losses++
+	winner.UpdatedAt, loser.UpdatedAt = now, now
+
+	if err := s.save(); err != nil {
+		*winner, *loser = oldWinner, oldLoser
+		// Images rated for the first time were never updated before
+		if oldWinner.UpdatedAt.IsZero() {
+			delete(s.byImage, winnerID)
+		}
+		if oldLoser.UpdatedAt.IsZero() {
+			delete(s.byImage, loserID)
+		}
+		return Rating{}, Rating{}, err
+	}
+	return *winner, *loser, nil
+}
+
+// Top returns up to limit of the highest rated images. Ties go to the
+// image with more duels.
+func (s *RatingStore) Top(limit int) []Rating {
+	s.mu.RLock()
+	ratings := make([]Rating, 0, len(s.byImage))
+	for _, r := range s.byImage {
+		ratings = append(ratings, *r)
+	}
+	s.mu.RUnlock()
+
+	sort.Slice(ratings, func(i, j int) bool {
+		a, b := ratings[i], ratings[j]
+		switch {
+		case a.Rating != b.Rating:
+			return a.Rating > b.Rating
+		case a.Wins+a.Losses != b.Wins+b.Losses:
+			return a.Wins+a.Losses > b.Wins+b.Losses
+		default:
+			return a.ImageID < b.ImageID
+		}
+	})
+	if limit > 0 && len(ratings) > limit {
+		ratings = ratings[:limit]
+	}
+	return ratings
+}
+
+// rating returns the stored rating of imageID, adding a default one.
+// Callers must hold s.mu.
+func (s *RatingStore) rating(imageID string) *Rating {
+	r, ok := s.byImage[imageID]
+	if !ok {
+		r = &Rating{ImageID: imageID, Rating: elo.DefaultRating}
+		s.byImage[imageID] = r
+	}
+	return r
+}
+
+// save writes all ratings to s.path via a temp file. Callers must hold s.mu.
+func (s *RatingStore) save() error {
+	if s.path == "" {
+		return nil
+	}
+
+	ratings := make([]*Rating, 0, len(s.byImage))
+	for _, r := range s.byImage {
+		ratings = append(ratings, r)
+	}
+	sort.Slice(ratings, func(i, j int) bool { return ratings[i].ImageID < ratings[j].ImageID })
+	data, err := json.MarshalIndent(ratings, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode rating store: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return fmt.Errorf("create rating store dir: %w", err)
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("write rating store: %w", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return fmt.Errorf("replace rating store: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,177 @@
+// Package models holds the app's locally stored data.
+package models
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	// ErrUserExists is returned when registering a taken username.
+	ErrUserExists = errors.New("username is already taken")
+	// ErrInvalidCredentials is returned for an unknown user or wrong password.
+	ErrInvalidCredentials = errors.New("invalid username or password")
+	// ErrInvalidUsername is returned for usernames outside usernamePattern.
+	ErrInvalidUsername = errors.New("username must be 3-32 letters, digits, '.', '_' or '-'")
+	// ErrWeakPassword is returned for passwords shorter than minPasswordLen.
+	ErrWeakPassword = errors.New("password must be at least 8 characters")
+)
+
+const minPasswordLen = 8
+
+var usernamePattern = regexp.MustCompile(`^[A-Za-z0-9._-]{3,32}$`)
+
+// User is a registered account. ID is what we send upstream as sub_id.
+type User struct {
+	ID           string    `json:"id"`
+	Username     string    `json:"username"`
+	PasswordHash []byte    `json:"password_hash"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// UserStore keeps users in memory and, when it has a path, persists them
+// to a JSON file after every change. It is safe for concurrent use.
+type UserStore struct {
+	path string
+
+	mu         sync.RWMutex
+	byID       map[string]*User
+	byUsername map[string]*User
+}
+
+// NewUserStore loads users from path. An empty path keeps users in memory
+// only; a missing file starts an empty store.
+func NewUserStore(path string) (*UserStore, error) {
+	s := &UserStore{
+		path:       path,
+		byID:       map[string]*User{},
+		byUsername: map[string]*User{},
+	}
+	if path == "" {
+		return s, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read user store: %w", err)
+	}
+
+	var users []*User
+	if err := json.Unmarshal(data, &users); err != nil {
+		return nil, fmt.Errorf("parse user store %s: %w", path, err)
+	}
+	for _, u := range users {
+		s.byID[u.ID] = u
+		s.byUsername[u.Username] = u
+	}
+	return s, nil
+}
+
+// Register creates a user with a bcrypt-hashed password.
+func (s *UserStore) Register(username, password string) (*User, error) {
+	if !usernamePattern.MatchString(username) {
+		return nil, ErrInvalidUsername
+	}
+	if len(password) < minPasswordLen {
+		return nil, ErrWeakPassword
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, fmt.Errorf("hash password: %w", err)
+	}
+	id, err := newUserID()
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.byUsername[username]; ok {
+		return nil, ErrUserExists
+	}
+	u := &User{ID: id, Username: username, PasswordHash: hash, CreatedAt: time.Now().UTC()}
+	s.byID[u.ID] = u
+	s.byUsername[u.Username] = u
+
+	if err := s.save(); err != nil {
+		delete(s.byID, u.ID)
+		delete(s.byUsername, u.Username)
+		return nil, err
+	}
+	return u, nil
+}
+
+// Authenticate returns the user when password matches.
+func (s *UserStore) Authenticate(username, password string) (*User, error) {
+	s.mu.RLock()
+	u, ok := s.byUsername[username]
+	s.mu.RUnlock()
+	if !ok {
+		return nil, ErrInvalidCredentials
+	}
+	if bcrypt.CompareHashAndPassword(u.PasswordHash, []byte(password)) != nil {
+		return nil, ErrInvalidCredentials
+	}
+	return u, nil
+}
+
+// Get returns the user with id.
+func (s *UserStore) Get(id string) (*User, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	u, ok := s.byID[id]
+	return u, ok
+}
+
+// save writes all users to s.path via a temp file. Callers must hold s.mu.
+func (s *UserStore) save() error {
+	if s.path == "" {
+		return nil
+	}
+
+	users := make([]*User, 0, len(s.byID))
+	for _, u := range s.byID {
+		users = append(users, u)
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].CreatedAt.Before(users[j].CreatedAt) })
+	data, err := json.MarshalIndent(users, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode user store: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return fmt.Errorf("create user store dir: %w", err)
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("write user store: %w", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return fmt.Errorf("replace user store: %w", err)
+	}
+	return nil
+}
+
+func newUserID() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generate user id: %w", err)
+	}
+	return "usr_" + hex.EncodeToString(b), nil
+}
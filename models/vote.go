@@ -0,0 +1,212 @@
+package models
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Vote is a like or dislike sent upstream through the app, kept locally
+// for the leaderboard. ID is the upstream vote ID.
+type Vote struct {
+	ID        int       `json:"id"`
+	ImageID   string    `json:"image_id"`
+	SubID     string    `json:"sub_id"`
+	Value     int       `json:"value"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ImageScore is an image's net likes over a leaderboard window.
+type ImageScore struct {
+	ImageID  string `json:"image_id"`
+	Score    int    `json:"score"`
+	Likes    int    `json:"likes"`
+	Dislikes int    `json:"dislikes"`
+	// LastVote breaks ties in favour of recently voted images
+	LastVote time.Time `json:"last_vote"`
+}
+
+// voteEvent is one line of the vote log: a vote added or, with Deleted
+// set, the vote with ID removed.
+type voteEvent struct {
+	Vote
+	Deleted bool `json:"deleted,omitempty"`
+}
+
+// VoteStore keeps votes in memory and, when it has a path, appends every
+// change to a JSON lines log that is replayed on start. Unlike UserStore
+// it never rewrites the file, since votes keep coming. It is safe for
+// concurrent use.
+type VoteStore struct {
+	path string
+
+	mu    sync.RWMutex
+	votes map[int]Vote
+}
+
+// NewVoteStore replays the vote log at path. An empty path keeps votes in
+// memory only; a missing file starts an empty store.
+func NewVoteStore(path string) (*VoteStore, error) {
+	s := &VoteStore{path: path, votes: map[int]Vote{}}
+	if path == "" {
+		return s, nil
+	}
+
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read vote store: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var e voteEvent
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			return nil, fmt.Errorf("parse vote store %s line %d: %w", path, line, err)
+		}
+		if e.Deleted {
+			delete(s.votes, e.ID)
+		} else {
+			s.votes[e.ID] = e.Vote
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read vote store: %w", err)
+	}
+	return s, nil
+}
+
+// Add records v, replacing any vote with the same ID.
+func (s *VoteStore) Add(v Vote) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.append(voteEvent{Vote: v}); err != nil {
+		return err
+	}
+	s.votes[v.ID] = v
+	return nil
+}
+
+// Remove forgets the vote with id. Unknown votes are ignored.
+func (s *VoteStore) Remove(id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.votes[id]; !ok {
+		return nil
+	}
+	if err := s.append(voteEvent{Vote: Vote{ID: id}, Deleted: true}); err != nil {
+		return err
+	}
+	delete(s.votes, id)
+	return nil
+}
+
+// voter is one user's say on one image
+type voter struct {
+	subID, imageID string
+}
+
+// Leaderboard returns up to limit images ranked by net likes from votes
+// cast at or after since; a zero since counts every vote. Only each
+// user's latest vote on an image counts, so voting again changes a
+// user's say rather than adding to it. Ties go to the image with more
+// likes, then the one voted on most recently.
+func (s *VoteStore) Leaderboard(since time.Time, limit int) []ImageScore {
+	s.mu.RLock()
+	latest := map[voter]Vote{}
+	for _, v := range s.votes {
+		key := voter{v.SubID, v.ImageID}
+		// Upstream IDs grow, so they order votes cast in the same instant
+		if prev, ok := latest[key]; ok &&
+			(prev.CreatedAt.After(v.CreatedAt) || prev.CreatedAt.Equal(v.CreatedAt) && prev.ID > v.ID) {
+			continue
+		}
+		latest[key] = v
+	}
+	s.mu.RUnlock()
+
+	byImage := map[string]*ImageScore{}
+	for _, v := range latest {
+		if v.CreatedAt.Before(since) {
+			continue
+		}
+		score, ok := byImage[v.ImageID]
+		if !ok {
+			score = &ImageScore{ImageID: v.ImageID}
+			byImage[v.ImageID] = score
+		}
+		switch {
+		case v.Value > 0:
+			score.Likes++
+		case v.Value < 0:
+			score.Dislikes++
+		}
+		if v.CreatedAt.After(score.LastVote) {
+			score.LastVote = v.CreatedAt
+		}
+	}
+
+	scores := make([]ImageScore, 0, len(byImage))
+	for _, score := range byImage {
+		score.Score = score.Likes - score.Dislikes
+		scores = append(scores, *score)
+	}
+	sort.Slice(scores, func(i, j int) bool {
+		a, b := scores[i], scores[j]
+		switch {
+		case a.Score != b.Score:
+			return a.Score > b.Score
+		case a.Likes != b.Likes:
+			return a.Likes > b.Likes
+		case !a.LastVote.Equal(b.LastVote):
+			return a.LastVote.After(b.LastVote)
+		default:
+			return a.ImageID < b.ImageID
+		}
+	})
+	if limit > 0 && len(scores) > limit {
+		scores = scores[:limit]
+	}
+	return scores
+}
+
+// append writes e to the end of the log. Callers must hold s.mu.
+func (s *VoteStore) append(e voteEvent) error {
+	if s.path == "" {
+		return nil
+	}
+
+	data, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("encode vote: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return fmt.Errorf("create vote store dir: %w", err)
+	}
+	f, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("open vote store: %w", err)
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		f.Close()
+		return fmt.Errorf("write vote store: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("write vote store: %w", err)
+	}
+	return nil
+}
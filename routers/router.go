@@ -14,8 +14,27 @@ func init() {
 	beego.Router("/", &controllers.MainController{})
 
 	beego.Router("/breed-search", &controllers.BreedSearchController{})
+	beego.Router("/breed-search/suggest", &controllers.BreedSearchController{}, "get:Suggest")
+	beego.Router("/breed-search/compare", &controllers.BreedSearchController{}, "get:Compare")
+	beego.Router("/breed-search/images", &controllers.BreedSearchController{}, "get:Images")
+	beego.Router("/breed-search/match", &controllers.BreedSearchController{}, "post:Match")
+	beego.Router("/breed-search/origins", &controllers.BreedSearchController{}, "get:Origins")
+	beego.Router("/breeds/:id", &controllers.BreedPageController{})
 
 	beego.Router("/voting", &controllers.VotingController{})
+	beego.Router("/voting/duel", &controllers.DuelController{})
+	beego.Router("/voting/duel/ranking", &controllers.DuelController{}, "get:Ranking")
 	beego.Router("/favourites", &controllers.FavoritesController{})
+	beego.Router("/favourites/:id", &controllers.FavoritesController{}, "delete:Delete")
+	beego.Router("/votes", &controllers.VotesController{})
+	beego.Router("/votes/:id", &controllers.VotesController{}, "delete:Delete;put:Put")
+	beego.Router("/leaderboard", &controllers.LeaderboardController{})
+
+	beego.Router("/register", &controllers.AuthController{}, "post:Register")
+	beego.Router("/login", &controllers.AuthController{}, "post:Login")
+	beego.Router("/logout", &controllers.AuthController{}, "post:Logout")
+	beego.Router("/me", &controllers.AuthController{}, "get:Me")
+
+	beego.Router("/debug/stats", &controllers.StatsController{})
 
 }
@@ -0,0 +1,117 @@
+// Package snapshot bundles an offline copy of the Cat API breed list so
+// breed search keeps working while the upstream is unreachable. The copy
+// is refreshed with the breedsnapshot command, which release builds must
+// run first (see .github/workflows/release.yml); until it is run the
+// embedded snapshot has no breeds.
+package snapshot
+
+//go:generate go run ../cmd/breedsnapshot -out breeds.json
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"myproject/catapi"
+)
+
+// Version is the snapshot file format written by Take. Files of any other
+// version are rejected by Decode.
+const Version = 1
+
+//go:embed breeds.json
+var breedsJSON []byte
+
+// Snapshot is a copy of /breeds taken at TakenAt from Source, with the
+// reference image of every breed that has one.
+type Snapshot struct {
+	Version int            `json:"version"`
+	TakenAt time.Time      `json:"taken_at"`
+	Source  string         `json:"source"`
+	Breeds  []catapi.Breed `json:"breeds"`
+}
+
+// Take downloads the breed list from client. Breeds listed without their
+// reference image have its metadata looked up; images the upstream no
+// longer has are left out.
+func Take(ctx context.Context, client *catapi.Client) (*Snapshot, error) {
+	breeds, err := client.ListBreeds(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list breeds: %w", err)
+	}
+	for i, b := range breeds {
+		if b.Image != nil || b.ReferenceImageID == "" {
+			continue
+		}
+		image, err := client.GetImage(ctx, b.ReferenceImageID)
+		if catapi.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("get reference image of %s: %w", b.ID, err)
+		}
+		breeds[i].Image = image
+	}
+	return &Snapshot{
+		Version: Version,
+		TakenAt: time.Now().UTC(),
+		Source:  client.BaseURL(),
+		Breeds:  breeds,
+	}, nil
+}
+
+// Decode reads a snapshot written by Take.
+func Decode(r io.Reader) (*Snapshot, error) {
+	var s Snapshot
+	if err := json.NewDecoder(r).Decode(&s); err != nil {
+		return nil, fmt.Errorf("snapshot: %w", err)
+	}
+	if s.Version != Version {
+		return nil, fmt.Errorf("snapshot: unsupported version %d, want %d", s.Version, Version)
+	}
+	return &s, nil
+}
+
+// Check reports whether s can stand in for the breed list: it must have
+// breeds and, if maxAge is positive, be taken no longer than maxAge before
+// now.
+func (s *Snapshot) Check(now time.Time, maxAge time.Duration) error {
+	if len(s.Breeds) == 0 {
+		return fmt.Errorf("snapshot has no breeds; run cmd/breedsnapshot")
+	}
+	if maxAge > 0 && now.Sub(s.TakenAt) > maxAge {
+		return fmt.Errorf("snapshot taken at %s is older than %s", s.TakenAt.Format(time.RFC3339), maxAge)
+	}
+	return nil
+}
+
+// Load reads the snapshot file at path.
+func Load(path string) (*Snapshot, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Decode(f)
+}
+
+var (
+	embeddedOnce sync.Once
+	embedded     *Snapshot
+	embeddedErr  error
+)
+
+// Embedded returns the snapshot built into the binary. Callers must not
+// modify it.
+func Embedded() (*Snapshot, error) {
+	embeddedOnce.Do(func() {
+		embedded, embeddedErr = Decode(bytes.NewReader(breedsJSON))
+	})
+	return embedded, embeddedErr
+}
@@ -0,0 +1,92 @@
+// Logged-in user, or null when browsing anonymously
+let currentUser = null;
+
+document.addEventListener('DOMContentLoaded', function() {
+    setupAuth();
+    loadCurrentUser();
+});
+
+function setupAuth() {
+    const form = document.getElementById('auth-form');
+    form.addEventListener('submit', async (e) => {
+        e.preventDefault();
+        const action = e.submitter ? e.submitter.getAttribute('data-action') : 'login';
+        await submitAuth(action);
+    });
+
+    document.getElementById('logout-btn').addEventListener('click', logout);
+}
+
+async function loadCurrentUser() {
+    try {
+        const response = await fetch('/me');
+        setCurrentUser(response.ok ? await response.json() : null);
+    } catch (error) {
+        console.error('Error loading current user:', error);
+    }
+}
+
+// Log in or register with the credentials in the form
+async function submitAuth(action) {
+    const username = document.getElementById('auth-username').value;
+    const password = document.getElementById('auth-password').value;
+
+    try {
+        const response = await fetch(action === 'register' ? '/register' : '/login', {
+            method: 'POST',
+            headers: {
+                'Content-Type': 'application/x-www-form-urlencoded',
+            },
+            body: new URLSearchParams({ username, password })
+        });
+        const data = await response.json();
+
+        if (data.error) {
+            showAuthMessage(data.error);
+            return;
+        }
+
+        document.getElementById('auth-password').value = '';
+        setCurrentUser(data);
+        if (currentPage === 'favorites') {
+            displayFavorites();
+        } else if (currentPage === 'voting') {
+            displayVoteHistory();
+        }
+    } catch (error) {
+        console.error('Error logging in:', error);
+    }
+}
+
+async function logout() {
+    try {
+        await fetch('/logout', { method: 'POST' });
+        setCurrentUser(null);
+        if (currentPage === 'favorites') {
+            displayFavorites();
+        } else if (currentPage === 'voting') {
+            displayVoteHistory();
+        }
+    } catch (error) {
+        console.error('Error logging out:', error);
+    }
+}
+
+function setCurrentUser(user) {
+    currentUser = user;
+    document.getElementById('auth-form').style.display = user ? 'none' : 'flex';
+    document.getElementById('account-info').style.display = user ? 'flex' : 'none';
+    document.getElementById('account-name').textContent = user ? user.username : '';
+    showAuthMessage('');
+}
+
+// Ask the user to log in, e.g. after a 401 from the server
+function promptLogin(message) {
+    setCurrentUser(null);
+    showAuthMessage(message);
+    document.getElementById('auth-username').focus();
+}
+
+function showAuthMessage(message) {
+    document.getElementById('auth-message').textContent = message;
+}
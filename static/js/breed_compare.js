@@ -0,0 +1,106 @@
+// Breeds picked for comparison, as {id, name}
+const MAX_COMPARE_BREEDS = 4;
+let compareSelection = [];
+
+const TRAIT_LABELS = {
+    weight: 'Weight',
+    life_span: 'Life span',
+    adaptability: 'Adaptability',
+    affection_level: 'Affection',
+    child_friendly: 'Child friendly',
+    cat_friendly: 'Cat friendly',
+    dog_friendly: 'Dog friendly',
+    energy_level: 'Energy',
+    grooming: 'Grooming',
+    health_issues: 'Health issues',
+    intelligence: 'Intelligence',
+    shedding_level: 'Shedding',
+    social_needs: 'Social needs',
+    stranger_friendly: 'Stranger friendly',
+    vocalisation: 'Vocalisation',
+    bidability: 'Bidability',
+};
+
+document.addEventListener("DOMContentLoaded", () => {
+    const addBtn = document.getElementById("compare-add-btn");
+    const runBtn = document.getElementById("compare-run-btn");
+    const selection = document.getElementById("compare-selection");
+
+    if (!addBtn || !runBtn || !selection) {
+        console.error("Comparison elements not found. Ensure IDs are correct.");
+        return;
+    }
+
+    addBtn.addEventListener("click", () => {
+        const select = document.querySelector("#breed-select");
+        const id = currentBreedId || select.value;
+        if (!id || compareSelection.some(b => b.id === id)) return;
+        if (compareSelection.length >= MAX_COMPARE_BREEDS) {
+            alert(`You can compare up to ${MAX_COMPARE_BREEDS} breeds.`);
+            return;
+        }
+        const option = Array.from(select.options).find(o => o.value === id);
+        compareSelection.push({ id, name: option ? option.textContent : id });
+        updateCompareSelection();
+    });
+
+    selection.addEventListener("click", (e) => {
+        const item = e.target.closest("li[data-id]");
+        if (!item) return;
+        compareSelection = compareSelection.filter(b => b.id !== item.dataset.id);
+        updateCompareSelection();
+    });
+
+    runBtn.addEventListener("click", compareBreeds);
+});
+
+function updateCompareSelection() {
+    document.getElementById("compare-selection").innerHTML = compareSelection.map(b =>
+        `<li data-id="${b.id}" title="Remove">${b.name} &times;</li>`
+    ).join('');
+    document.getElementById("compare-run-btn").disabled = compareSelection.length < 2;
+}
+
+async function compareBreeds() {
+    const ids = compareSelection.map(b => b.id).join(',');
+    try {
+        const response = await fetch(`/breed-search/compare?ids=${encodeURIComponent(ids)}`);
+        const data = await response.json();
+        if (!response.ok) {
+            throw new Error(data.error || response.statusText);
+        }
+        displayComparison(data);
+    } catch (error) {
+        console.error('Error comparing breeds:', error);
+    }
+}
+
+function displayComparison(comparison) {
+    const header = comparison.breeds.map(b => `
+        <th>
+            ${b.image ? `<img src="${b.image.url}" alt="${b.name}">` : ''}
+            <a href="/breeds/${b.id}">${b.name}</a>
+            <small>${comparison.wins[b.id]} wins</small>
+        </th>`
+    ).join('');
+
+    const rows = comparison.rows.map(row => {
+        const cells = comparison.breeds.map((b, i) => {
+            const display = row.display[i] || '&ndash;';
+            const unit = row.unit && row.display[i] ? ` ${row.unit}` : '';
+            const normalized = row.normalized[i];
+            const winner = row.winners.includes(b.id) ? ' class="compare-winner"' : '';
+            const bar = normalized === null ? '' :
+                `<span class="rating-bar"><span style="width: ${Math.round(normalized * 100)}%"></span></span>`;
+            return `<td${winner}>${display}${unit}${bar}</td>`;
+        }).join('');
+        const hint = row.better === 'lower' ? ' <small>(lower is better)</small>' : '';
+        return `<tr><th>${TRAIT_LABELS[row.trait] || row.trait}${hint}</th>${cells}</tr>`;
+    }).join('');
+
+    document.getElementById("compare-result").innerHTML = `
+        <table class="compare-table">
+            <thead><tr><th></th>${header}</tr></thead>
+            <tbody>${rows}</tbody>
+        </table>`;
+}
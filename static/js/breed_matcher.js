@@ -0,0 +1,53 @@
+// Breed matcher questionnaire, answered by /breed-search/match
+document.addEventListener("DOMContentLoaded", () => {
+    const form = document.getElementById("breed-matcher");
+    if (!form) {
+        console.error("Breed matcher form not found. Ensure IDs are correct.");
+        return;
+    }
+
+    form.addEventListener("submit", async (e) => {
+        e.preventDefault();
+        const data = new FormData(form);
+        const params = new URLSearchParams();
+        for (const name of ["apartment_size", "time_at_home"]) {
+            if (data.get(name)) params.set(name, data.get(name));
+        }
+        for (const name of ["allergies", "kids"]) {
+            params.set(name, data.has(name) ? "true" : "false");
+        }
+        const pets = data.getAll("other_pets");
+        if (pets.length) params.set("other_pets", pets.join(","));
+
+        try {
+            const response = await fetch("/breed-search/match", { method: "POST", body: params });
+            const result = await response.json();
+            if (!response.ok) {
+                throw new Error(result.error || response.statusText);
+            }
+            displayMatches(result.matches);
+        } catch (error) {
+            console.error("Error matching breeds:", error);
+        }
+    });
+
+    document.getElementById("matcher-results").addEventListener("click", async (e) => {
+        const item = e.target.closest("li[data-id]");
+        if (!item) return;
+        document.querySelector("#breed-select").value = item.dataset.id;
+        await loadBreedDetails(item.dataset.id);
+    });
+});
+
+function displayMatches(matches) {
+    document.getElementById("matcher-results").innerHTML = matches.map(m => `
+        <li data-id="${m.id}">
+            <strong>${m.name}</strong> <span class="match-score">${Math.round(m.score)}% match</span>
+            <ul class="match-breakdown">
+                ${m.breakdown.slice(0, 3).map(item =>
+                    `<li>${item.reason} (${item.trait.replace(/_/g, ' ')} ${item.level}/5)</li>`
+                ).join('')}
+            </ul>
+        </li>`
+    ).join('');
+}
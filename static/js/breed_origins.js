@@ -0,0 +1,33 @@
+// Breed origins by country, from /breed-search/origins
+let originsLoaded = false;
+
+// countryFlag turns a two-letter country code into its flag emoji
+function countryFlag(code) {
+    if (!code || code.length !== 2) return "🏳️";
+    return String.fromCodePoint(...[...code.toUpperCase()].map(c => 0x1F1E6 + c.charCodeAt(0) - 65));
+}
+
+async function loadOrigins() {
+    originsLoaded = true;
+    try {
+        const response = await fetch("/breed-search/origins");
+        const origins = await response.json();
+        if (!response.ok) {
+            throw new Error(origins.error || response.statusText);
+        }
+        const most = Math.max(...origins.map(o => o.count), 1);
+        document.getElementById("origin-map").innerHTML = origins.map(o => `
+            <li style="--weight: ${o.count / most}">
+                ${o.image ? `<img src="${o.image.url}" alt="${o.country}">` : ''}
+                <span class="origin-country">${countryFlag(o.country_code)} ${o.country}</span>
+                <span class="origin-count">${o.count} ${o.count === 1 ? 'breed' : 'breeds'}</span>
+                <span class="origin-breeds">
+                    ${o.breeds.map(b => `<a href="/breeds/${b.id}">${b.name}</a>`).join(', ')}
+                </span>
+            </li>`
+        ).join('');
+    } catch (error) {
+        originsLoaded = false;
+        console.error("Error loading breed origins:", error);
+    }
+}
@@ -0,0 +1,121 @@
+// Duel state
+const DUEL_RANKING_LIMIT = 10;
+let votingMode = "single";
+let duelImages = [];
+
+document.addEventListener("DOMContentLoaded", () => {
+    const modes = document.getElementById("voting-modes");
+    const images = document.getElementById("duel-images");
+
+    if (!modes || !images || !document.getElementById("duel-mode") || !document.getElementById("duel-ranking")) {
+        console.error("One or more duel elements not found. Ensure IDs are correct.");
+        return;
+    }
+
+    modes.addEventListener("click", (e) => {
+        const button = e.target.closest("button[data-mode]");
+        if (!button || button.dataset.mode === votingMode) {
+            return;
+        }
+        votingMode = button.dataset.mode;
+        modes.querySelectorAll("button").forEach(b => b.classList.toggle("active", b === button));
+        document.getElementById("single-mode").hidden = votingMode !== "single";
+        document.getElementById("duel-mode").hidden = votingMode !== "duel";
+        if (votingMode === "duel") {
+            loadDuel();
+        } else {
+            loadRandomCat();
+        }
+    });
+
+    images.addEventListener("click", (e) => {
+        const picked = e.target.closest("[data-image-id]");
+        if (picked) {
+            pickDuelWinner(picked.dataset.imageId);
+        }
+    });
+});
+
+// Fetch two new contenders and the current ranking
+async function loadDuel() {
+    const images = document.getElementById("duel-images");
+    loadDuelRanking();
+    try {
+        const response = await fetch("/voting/duel");
+        const data = await response.json();
+        if (!response.ok) {
+            throw new Error(data.error || response.statusText);
+        }
+
+        duelImages = data.images;
+        images.innerHTML = duelImages.map(image =>
+            `<button class="duel-image" data-image-id="${image.id}">
+                <img src="${image.url}" alt="Cat rated ${Math.round(image.rating)}">
+                <span class="duel-rating">${Math.round(image.rating)}</span>
+            </button>`
+        ).join("");
+    } catch (error) {
+        console.error("Error fetching duel:", error);
+        images.innerHTML = "<p>Error loading cats. Please try again later.</p>";
+    }
+}
+
+// Record winnerId as the cuter of the two contenders and start a new duel
+async function pickDuelWinner(winnerId) {
+    const loser = duelImages.find(image => image.id !== winnerId);
+    if (!loser) {
+        return;
+    }
+    try {
+        const response = await fetch("/voting/duel", {
+            method: "POST",
+            headers: {
+                "Content-Type": "application/x-www-form-urlencoded",
+            },
+            body: `winner_id=${encodeURIComponent(winnerId)}&loser_id=${encodeURIComponent(loser.id)}`
+        });
+
+        if (response.status === 401) {
+            promptLogin("Log in to vote.");
+            return;
+        }
+
+        const data = await response.json();
+        if (data.error) {
+            console.error("Error recording duel:", data.error);
+            return;
+        }
+    } catch (error) {
+        console.error("Error recording duel:", error);
+        return;
+    }
+    loadDuel();
+}
+
+// Fetch the highest rated images
+async function loadDuelRanking() {
+    const list = document.getElementById("duel-ranking");
+    try {
+        const response = await fetch(`/voting/duel/ranking?limit=${DUEL_RANKING_LIMIT}`);
+        const data = await response.json();
+        if (!response.ok) {
+            throw new Error(data.error || response.statusText);
+        }
+
+        if (data.length > 0) {
+            list.innerHTML = data.map(entry =>
+                `<li data-image-id="${entry.image_id}">
+                    <span class="leaderboard-rank">${entry.rank}</span>
+                    ${entry.image_url ? `<img src="${entry.image_url}" alt="Cat ranked ${entry.rank}" width="80" height="80">` : ""}
+                    <span class="leaderboard-score">${Math.round(entry.rating)}</span>
+                    <span class="leaderboard-votes">${entry.wins}–${entry.losses}</span>
+                </li>`
+            ).join("");
+        } else {
+            list.innerHTML = "<p>No duels yet. Be the first!</p>";
+        }
+    } catch (error) {
+        console.error("Error fetching duel ranking:", error);
+        list.innerHTML = "<p>Error loading the ranking. Please try again later.</p>";
+    }
+}
@@ -1,9 +1,16 @@
+// Favorites paging state
+const FAVORITES_PAGE_SIZE = 12;
+let favoritesPage = 0;
+let favoritesCount = 0;
+
 document.addEventListener("DOMContentLoaded", () => {
     const gridViewBtn = document.getElementById("grid-view-btn");
     const columnViewBtn = document.getElementById("column-view-btn");
     const favoritesList = document.getElementById("favorites-list");
+    const prevBtn = document.getElementById("fav-prev-btn");
+    const nextBtn = document.getElementById("fav-next-btn");
   
-    if (!gridViewBtn || !columnViewBtn || !favoritesList) {
+    if (!gridViewBtn || !columnViewBtn || !favoritesList || !prevBtn || !nextBtn) {
       console.error("One or more elements not found. Ensure IDs are correct.");
       return;
     }
@@ -24,5 +31,93 @@ document.addEventListener("DOMContentLoaded", () => {
       favoritesList.classList.add("column-view");
       console.log("Switched to column view");
     });
+
+    // Page through favorites
+    prevBtn.addEventListener("click", () => {
+      if (favoritesPage > 0) {
+        favoritesPage--;
+        displayFavorites();
+      }
+    });
+
+    nextBtn.addEventListener("click", () => {
+      if ((favoritesPage + 1) * FAVORITES_PAGE_SIZE < favoritesCount) {
+        favoritesPage++;
+        displayFavorites();
+      }
+    });
   });
-  
\ No newline at end of file
+
+// Fetch the current page of favorites, newest first
+async function displayFavorites() {
+    const favoritesList = document.getElementById("favorites-list");
+    try {
+        const response = await fetch(`/favourites?page=${favoritesPage}&limit=${FAVORITES_PAGE_SIZE}&order=DESC`);
+        if (response.status === 401) {
+            favoritesCount = 0;
+            updateFavoritesPager();
+            favoritesList.innerHTML = "<p>Log in to see your favorite cat images.</p>";
+            return;
+        }
+        const favorites = await response.json();
+        if (!response.ok) {
+            throw new Error(favorites.error || response.statusText);
+        }
+        favoritesCount = parseInt(response.headers.get("Pagination-Count"), 10) || favorites.length;
+
+        // Step back if the current page emptied out, e.g. after a delete
+        if (favorites.length === 0 && favoritesPage > 0) {
+            favoritesPage--;
+            return displayFavorites();
+        }
+
+        if (favorites.length > 0) {
+            favoritesList.innerHTML = favorites.map(favorite => 
+                `<li data-id="${favorite.id}">
+                    <img src="${favorite.image.url}" 
+                         alt="Favorite Cat Image" 
+                         width="200">
+                    <button class="fav-delete-btn" onclick="deleteFavorite(${favorite.id})" title="Remove from favorites">
+                        <i class="fas fa-trash"></i>
+                    </button>
+                </li>`
+            ).join("");
+        } else {
+            favoritesList.innerHTML = "<p>You have no favorite cat images yet.</p>";
+        }
+        updateFavoritesPager();
+    } catch (error) {
+        console.error("Error fetching favorites:", error);
+        favoritesList.innerHTML = "<p>Error loading favorite images. Please try again later.</p>";
+    }
+}
+
+// Show the page position and enable the buttons that make sense
+function updateFavoritesPager() {
+    const pages = Math.max(1, Math.ceil(favoritesCount / FAVORITES_PAGE_SIZE));
+    document.getElementById("fav-page-info").textContent = `Page ${favoritesPage + 1} of ${pages}`;
+    document.getElementById("fav-prev-btn").disabled = favoritesPage === 0;
+    document.getElementById("fav-next-btn").disabled = favoritesPage + 1 >= pages;
+    document.getElementById("favorites-pager").style.display = favoritesCount > FAVORITES_PAGE_SIZE ? "flex" : "none";
+}
+
+// Remove a favorite and reload the current page
+async function deleteFavorite(id) {
+    try {
+        const response = await fetch(`/favourites/${id}`, { method: "DELETE" });
+        if (response.status === 401) {
+            promptLogin("Log in to manage your favorites.");
+            return;
+        }
+
+        const data = await response.json();
+        if (data.error) {
+            console.error("Error deleting favorite:", data.error);
+            return;
+        }
+
+        displayFavorites();
+    } catch (error) {
+        console.error("Error deleting favorite:", error);
+    }
+}
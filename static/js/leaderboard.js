@@ -0,0 +1,50 @@
+// Leaderboard state
+const LEADERBOARD_LIMIT = 20;
+let leaderboardWindow = "week";
+
+document.addEventListener("DOMContentLoaded", () => {
+    const windows = document.getElementById("leaderboard-windows");
+
+    if (!windows || !document.getElementById("leaderboard-list")) {
+        console.error("One or more leaderboard elements not found. Ensure IDs are correct.");
+        return;
+    }
+
+    windows.addEventListener("click", (e) => {
+        const button = e.target.closest("button[data-window]");
+        if (!button) {
+            return;
+        }
+        leaderboardWindow = button.dataset.window;
+        windows.querySelectorAll("button").forEach(b => b.classList.toggle("active", b === button));
+        loadLeaderboard();
+    });
+});
+
+// Fetch the most liked images of the selected window
+async function loadLeaderboard() {
+    const list = document.getElementById("leaderboard-list");
+    try {
+        const response = await fetch(`/leaderboard?window=${leaderboardWindow}&limit=${LEADERBOARD_LIMIT}`);
+        const data = await response.json();
+        if (!response.ok) {
+            throw new Error(data.error || response.statusText);
+        }
+
+        if (data.entries.length > 0) {
+            list.innerHTML = data.entries.map(entry =>
+                `<li data-image-id="${entry.image_id}">
+                    <span class="leaderboard-rank">${entry.rank}</span>
+                    ${entry.image_url ? `<img src="${entry.image_url}" alt="Cat ranked ${entry.rank}" width="120" height="120">` : ""}
+                    <span class="leaderboard-score">${entry.score > 0 ? "+" : ""}${entry.score}</span>
+                    <span class="leaderboard-votes">👍 ${entry.likes} 👎 ${entry.dislikes}</span>
+                </li>`
+            ).join("");
+        } else {
+            list.innerHTML = "<p>No votes yet. Be the first!</p>";
+        }
+    } catch (error) {
+        console.error("Error fetching leaderboard:", error);
+        list.innerHTML = "<p>Error loading the leaderboard. Please try again later.</p>";
+    }
+}
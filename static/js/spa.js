@@ -9,6 +9,9 @@ let currentBreedId = null;
 document.addEventListener('DOMContentLoaded', function() {
     setupNavigation();
     setupBreedSelect();
+    setupBreedSuggest();
+    setupBreedLinks();
+    document.querySelector('#gallery-more-btn').addEventListener('click', loadMoreBreedImages);
     loadInitialPage();
 });
 
@@ -18,11 +21,32 @@ function setupNavigation() {
         link.addEventListener('click', (e) => {
             e.preventDefault();
             const page = e.currentTarget.getAttribute('data-page');
+            history.pushState({ page }, '', `/#${page}`);
             navigateToPage(page);
         });
     });
 }
 
+// Links to /breeds/:id open the breed inside the app. The server renders
+// the same URL as a standalone page, so shared links and reloads work too.
+function setupBreedLinks() {
+    document.addEventListener('click', (e) => {
+        const link = e.target.closest('a[href^="/breeds/"]');
+        if (!link || e.button !== 0 || e.metaKey || e.ctrlKey || e.shiftKey || e.altKey) return;
+        e.preventDefault();
+        openBreed(decodeURIComponent(link.getAttribute('href').slice('/breeds/'.length)));
+    });
+}
+
+// Show a breed on the breeds page and record it in the address bar
+async function openBreed(breedId) {
+    if (window.location.pathname !== `/breeds/${encodeURIComponent(breedId)}`) {
+        history.pushState({ breedId }, '', `/breeds/${encodeURIComponent(breedId)}`);
+    }
+    currentBreedId = breedId;
+    await navigateToPage('breeds');
+}
+
 // Update page navigation function
 async function navigateToPage(page) {
     // Hide all content sections
@@ -51,9 +75,17 @@ async function navigateToPage(page) {
     // Load page-specific content
     switch(page) {
         case 'voting':
-            await loadRandomCat();
+            displayVoteHistory();
+            if (votingMode === 'duel') {
+                loadDuel();
+            } else {
+                await loadRandomCat();
+            }
             break;
         case 'breeds':
+            if (!originsLoaded) {
+                loadOrigins();
+            }
             if (!document.querySelector('#breed-select').options.length) {
                 await loadBreeds();
             } else if (currentBreedId) {
@@ -64,6 +96,9 @@ async function navigateToPage(page) {
         case 'favorites':
             displayFavorites();
             break;
+        case 'leaderboard':
+            loadLeaderboard();
+            break;
     }
 
     currentPage = page;
@@ -95,6 +130,7 @@ async function loadRandomCat() {
 
 
 async function handleVote(action) {
+    const votedImage = { imageUrl: currentImageUrl, imageId: currentImageId };
     try {
         // Send vote action (like/dislike) with image_id to the backend
         const response = await fetch('/voting', {
@@ -105,13 +141,31 @@ async function handleVote(action) {
             body: `action=${action}&image_url=${currentImageUrl}&image_id=${currentImageId}`
         });
 
+        if (response.status === 401) {
+            promptLogin('Log in to vote.');
+            return;
+        }
+
         const data = await response.json();
 
+        if (data.vote_id) {
+            offerUndo({ id: data.vote_id, action, seconds: data.undo_seconds, ...votedImage });
+        }
+
+        if (data.error) {
+            console.error('Error handling vote:', data.error);
+            return;
+        }
+
         // Handle response data (update image)
         currentImageUrl = data.image_url;
         currentImageId = data.image_id; // Update image ID as well
         document.getElementById('voting-image').src = data.image_url;
 
+        // The newest vote leads the history
+        historyPage = 0;
+        displayVoteHistory();
+
     } catch (error) {
         console.error('Error handling vote:', error);
     }
@@ -127,8 +181,19 @@ async function handleFavorite() {
             },
             body: `action=favorite&image_url=${currentImageUrl}&image_id=${currentImageId}`
         });
+
+        if (response.status === 401) {
+            promptLogin('Log in to save favorites.');
+            return;
+        }
+
         const data = await response.json();
 
+        if (data.error) {
+            console.error('Error handling favorite:', data.error);
+            return;
+        }
+
         currentImageUrl = data.image_url;
         currentImageId = data.image_id; // Update the image ID for the next interaction
         document.getElementById('voting-image').src = data.image_url;
@@ -147,11 +212,71 @@ async function handleFavorite() {
 async function setupBreedSelect() {
     const select = document.querySelector('#breed-select');
     select.addEventListener('change', async (e) => {
-        const breedId = e.target.value;
-        await loadBreedDetails(breedId);
+        await openBreed(e.target.value);
     });
 }
 
+// Debounced autocomplete over /breed-search/suggest
+let suggestTimer = null;
+
+function setupBreedSuggest() {
+    const input = document.querySelector('#breed-search-input');
+    const list = document.querySelector('#breed-suggestions');
+
+    input.addEventListener('input', () => {
+        clearTimeout(suggestTimer);
+        const q = input.value.trim();
+        if (!q) {
+            list.innerHTML = '';
+            return;
+        }
+        suggestTimer = setTimeout(() => fetchBreedSuggestions(q), 200);
+    });
+
+    list.addEventListener('click', async (e) => {
+        const item = e.target.closest('li[data-id]');
+        if (!item) return;
+        list.innerHTML = '';
+        input.value = '';
+        await openBreed(item.dataset.id);
+    });
+}
+
+async function fetchBreedSuggestions(q) {
+    try {
+        const response = await fetch(`/breed-search/suggest?q=${encodeURIComponent(q)}&limit=8`);
+        if (!response.ok) {
+            throw new Error(`Failed to fetch suggestions: ${response.statusText}`);
+        }
+        const suggestions = await response.json();
+
+        const list = document.querySelector('#breed-suggestions');
+        list.innerHTML = suggestions.map(s => `
+            <li data-id="${s.id}">
+                <span class="suggestion-name">${s.name}</span>
+                ${s.field !== 'name' ? `<span class="suggestion-match">${s.match}</span>` : ''}
+            </li>`
+        ).join('');
+    } catch (error) {
+        console.error('Error loading breed suggestions:', error);
+    }
+}
+
+// showStaleNotice warns when breed data was served stale, e.g. from the
+// offline snapshot while The Cat API is unreachable
+function showStaleNotice(response) {
+    const notice = document.getElementById('breeds-stale');
+    if (!notice) return;
+    if (response.headers.get('X-Cache') !== 'STALE') {
+        notice.hidden = true;
+        return;
+    }
+    const savedAt = response.headers.get('X-Breeds-Snapshot');
+    notice.textContent = 'The Cat API could not be reached, so breed data may be out of date' +
+        (savedAt ? ` (saved ${new Date(savedAt).toLocaleDateString()}).` : '.');
+    notice.hidden = false;
+}
+
 async function loadBreeds() {
     try {
         // Fetch breed list from the Go server's BreedSearchController (GET method)
@@ -159,6 +284,7 @@ async function loadBreeds() {
         if (!response.ok) {
             throw new Error(`Failed to fetch breeds: ${response.statusText}`);
         }
+        showStaleNotice(response);
 
         const breeds = await response.json();
 
@@ -167,15 +293,67 @@ async function loadBreeds() {
             `<option value="${breed.id}">${breed.name}</option>`
         ).join('');
 
-        // Automatically load details of the first breed
+        // Show the breed from the URL, or else the first one
         if (breeds.length > 0) {
-            await loadBreedDetails(breeds[0].id);
+            await loadBreedDetails(currentBreedId || breeds[0].id);
         }
     } catch (error) {
         console.error('Error loading breeds:', error);
     }
 }
 
+// Trait ratings shown in the breed profile, in display order
+const BREED_RATINGS = [
+    ['affection_level', 'Affection'],
+    ['energy_level', 'Energy'],
+    ['intelligence', 'Intelligence'],
+    ['child_friendly', 'Child friendly'],
+    ['dog_friendly', 'Dog friendly'],
+    ['stranger_friendly', 'Stranger friendly'],
+    ['adaptability', 'Adaptability'],
+    ['social_needs', 'Social needs'],
+    ['grooming', 'Grooming'],
+    ['shedding_level', 'Shedding'],
+    ['vocalisation', 'Vocalisation'],
+    ['health_issues', 'Health issues'],
+];
+
+const BREED_FLAGS = [
+    ['hypoallergenic', 'Hypoallergenic'],
+    ['indoor', 'Indoor'],
+    ['lap', 'Lap cat'],
+    ['hairless', 'Hairless'],
+    ['rare', 'Rare'],
+    ['natural', 'Natural breed'],
+    ['short_legs', 'Short legs'],
+];
+
+function displayBreedProfile(breed) {
+    const facts = [
+        ['Temperament', breed.temperament],
+        ['Life span', breed.life_span ? `${breed.life_span} years` : ''],
+        ['Weight', breed.weight && breed.weight.metric ? `${breed.weight.metric} kg` : ''],
+    ].filter(([, value]) => value);
+    document.querySelector('.breed-facts').innerHTML = facts.map(([label, value]) =>
+        `<dt>${label}</dt><dd>${value}</dd>`
+    ).join('');
+
+    document.querySelector('.breed-flags').innerHTML = BREED_FLAGS
+        .filter(([key]) => breed[key])
+        .map(([, label]) => `<li>${label}</li>`)
+        .join('');
+
+    document.querySelector('.breed-ratings').innerHTML = BREED_RATINGS
+        .filter(([key]) => breed[key] > 0)
+        .map(([key, label]) => `
+            <li>
+                <span class="rating-label">${label}</span>
+                <span class="rating-bar"><span style="width: ${breed[key] * 20}%"></span></span>
+                <span class="rating-value">${breed[key]}/5</span>
+            </li>`)
+        .join('');
+}
+
 // Update loadBreedDetails function
 async function loadBreedDetails(breedId) {
     try {
@@ -190,15 +368,19 @@ async function loadBreedDetails(breedId) {
         if (!response.ok) {
             throw new Error(`Failed to fetch breed details: ${response.statusText}`);
         }
+        showStaleNotice(response);
 
         const data = await response.json();
         const selectedBreed = data.breed;
         const breedImages = data.images;
+        currentBreedId = selectedBreed.id;
+        document.querySelector('#breed-select').value = selectedBreed.id;
 
         // Update breed details
         document.querySelector('.breed-title').innerHTML = 
-            `${selectedBreed.name} ${selectedBreed.origin ? `(${selectedBreed.origin})` : ''} <span class="breed-id">${selectedBreed.id}</span>`;
+            `<a href="/breeds/${selectedBreed.id}" class="breed-page-link">${selectedBreed.name}</a> ${selectedBreed.origin ? `(${selectedBreed.origin})` : ''} <span class="breed-id">${selectedBreed.id}</span>`;
         document.querySelector('.breed-description').textContent = selectedBreed.description;
+        displayBreedProfile(selectedBreed);
 
         const wikiLink = document.querySelector('.wiki-link');
         if (selectedBreed.wikipedia_url) {
@@ -208,70 +390,82 @@ async function loadBreedDetails(breedId) {
             wikiLink.style.display = 'none';
         }
 
-        // Update breed images in the Swiper carousel
-        const swiperWrapper = document.querySelector('.swiper-wrapper');
-        swiperWrapper.innerHTML = breedImages.map(image => 
-            `<div class="swiper-slide"><img src="${image.url}" alt="Breed Image"></div>`
-        ).join('');
-
-        // Reinitialize Swiper with updated slides
-        if (swiper) {
-            swiper.destroy(true, true);
-        }
-
-        swiper = new Swiper('.swiper', {
-            slidesPerView: 1,
-            spaceBetween: 0,
-            loop: true,
-            autoplay: {
-                delay: 2000,
-                disableOnInteraction: false,
-                pauseOnMouseEnter: true,
-            },
-            pagination: {
-                el: '.swiper-pagination',
-                clickable: true,
-            },
-        });
+        galleryImages = breedImages;
+        galleryPage = data.pagination;
+        renderGallery();
 
     } catch (error) {
         console.error('Error loading breed details:', error);
     }
 }
 
-// Favorites page functions
-// Update displayFavorites to fetch from API
-async function displayFavorites() {
-    const favoritesList = document.getElementById('favorites-list');
+// Breed gallery state; more pages are appended by loadMoreBreedImages
+let galleryImages = [];
+let galleryPage = null;
+
+function renderGallery() {
+    // Update breed images in the Swiper carousel
+    const swiperWrapper = document.querySelector('.swiper-wrapper');
+    swiperWrapper.innerHTML = galleryImages.map(image => 
+        `<div class="swiper-slide"><img src="${image.url}" alt="Breed Image"></div>`
+    ).join('');
+
+    // Reinitialize Swiper with updated slides
+    if (swiper) {
+        swiper.destroy(true, true);
+    }
+
+    swiper = new Swiper('.swiper', {
+        slidesPerView: 1,
+        spaceBetween: 0,
+        loop: true,
+        autoplay: {
+            delay: 2000,
+            disableOnInteraction: false,
+            pauseOnMouseEnter: true,
+        },
+        pagination: {
+            el: '.swiper-pagination',
+            clickable: true,
+        },
+    });
+
+    const moreBtn = document.querySelector('#gallery-more-btn');
+    moreBtn.style.display = galleryPage && galleryPage.has_more ? 'inline-block' : 'none';
+}
+
+async function loadMoreBreedImages() {
+    if (!currentBreedId || !galleryPage) return;
+    const params = new URLSearchParams({
+        breed_id: currentBreedId,
+        page: galleryPage.page + 1,
+        limit: galleryPage.limit,
+        order: galleryPage.order,
+    });
     try {
-        // Fetch favorites from the API
-        const response = await fetch('/favourites');
-        const favorites = await response.json();
-
-        if (favorites.length > 0) {
-            // Sort by created_at in reverse chronological order
-            const sortedFavorites = favorites.sort((a, b) => 
-                new Date(b.created_at) - new Date(a.created_at)
-            );
-
-            favoritesList.innerHTML = sortedFavorites.map(favorite => 
-                `<li>
-                    <img src="${favorite.image.url}" 
-                         alt="Favorite Cat Image" 
-                         width="200">
-                </li>`
-            ).join('');
-        } else {
-            favoritesList.innerHTML = '<p>You have no favorite cat images yet.</p>';
+        const response = await fetch(`/breed-search/images?${params}`);
+        const data = await response.json();
+        if (!response.ok) {
+            throw new Error(data.error || response.statusText);
         }
+        galleryImages = galleryImages.concat(data.images);
+        galleryPage = data.pagination;
+        renderGallery();
+        swiper.slideToLoop(galleryImages.length - data.images.length, 0);
     } catch (error) {
-        console.error('Error fetching favorites:', error);
-        favoritesList.innerHTML = '<p>Error loading favorite images. Please try again later.</p>';
+        console.error('Error loading more breed images:', error);
     }
 }
 
-// Load initial page based on URL hash or default to voting
+// Load the page for the URL: /breeds/:id opens that breed, otherwise the
+// hash names the page, defaulting to voting
 function loadInitialPage() {
+    const match = window.location.pathname.match(/^\/breeds\/([^/]+)$/);
+    if (match) {
+        currentBreedId = decodeURIComponent(match[1]);
+        navigateToPage('breeds');
+        return;
+    }
     const hash = window.location.hash.slice(1);
     navigateToPage(hash || 'voting');
 }
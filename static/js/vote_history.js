@@ -0,0 +1,73 @@
+// Vote history paging state
+const HISTORY_PAGE_SIZE = 10;
+let historyPage = 0;
+let historyCount = 0;
+
+document.addEventListener("DOMContentLoaded", () => {
+    const prevBtn = document.getElementById("history-prev-btn");
+    const nextBtn = document.getElementById("history-next-btn");
+
+    if (!prevBtn || !nextBtn || !document.getElementById("vote-history-list")) {
+        console.error("One or more vote history elements not found. Ensure IDs are correct.");
+        return;
+    }
+
+    prevBtn.addEventListener("click", () => {
+        if (historyPage > 0) {
+            historyPage--;
+            displayVoteHistory();
+        }
+    });
+
+    nextBtn.addEventListener("click", () => {
+        if ((historyPage + 1) * HISTORY_PAGE_SIZE < historyCount) {
+            historyPage++;
+            displayVoteHistory();
+        }
+    });
+});
+
+// Fetch the current page of the user's votes, newest first
+async function displayVoteHistory() {
+    const historyList = document.getElementById("vote-history-list");
+    try {
+        const response = await fetch(`/votes?page=${historyPage}&limit=${HISTORY_PAGE_SIZE}&order=DESC`);
+        if (response.status === 401) {
+            historyPage = 0;
+            historyCount = 0;
+            updateHistoryPager();
+            historyList.innerHTML = "<p>Log in to see your votes.</p>";
+            return;
+        }
+        const votes = await response.json();
+        if (!response.ok) {
+            throw new Error(votes.error || response.statusText);
+        }
+        historyCount = parseInt(response.headers.get("Pagination-Count"), 10) || votes.length;
+
+        if (votes.length > 0) {
+            historyList.innerHTML = votes.map(vote =>
+                `<li data-id="${vote.id}" class="${vote.value > 0 ? "liked" : "disliked"}">
+                    ${vote.image.url ? `<img src="${vote.image.url}" alt="Cat you voted on" width="80" height="80">` : ""}
+                    <span class="vote-value">${vote.value > 0 ? "👍" : "👎"}</span>
+                    <time datetime="${vote.created_at}">${new Date(vote.created_at).toLocaleString()}</time>
+                </li>`
+            ).join("");
+        } else {
+            historyList.innerHTML = "<p>You haven't voted yet.</p>";
+        }
+        updateHistoryPager();
+    } catch (error) {
+        console.error("Error fetching votes:", error);
+        historyList.innerHTML = "<p>Error loading your votes. Please try again later.</p>";
+    }
+}
+
+// Show the page position and enable the buttons that make sense
+function updateHistoryPager() {
+    const pages = Math.max(1, Math.ceil(historyCount / HISTORY_PAGE_SIZE));
+    document.getElementById("history-page-info").textContent = `Page ${historyPage + 1} of ${pages}`;
+    document.getElementById("history-prev-btn").disabled = historyPage === 0;
+    document.getElementById("history-next-btn").disabled = historyPage + 1 >= pages;
+    document.getElementById("vote-history-pager").style.display = historyCount > HISTORY_PAGE_SIZE ? "flex" : "none";
+}
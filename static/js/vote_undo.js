@@ -0,0 +1,82 @@
+// The latest vote, while it may still be undone or changed
+let lastVote = null;
+let undoTimer = null;
+
+document.addEventListener("DOMContentLoaded", () => {
+    const undoBtn = document.getElementById("vote-undo-btn");
+    const changeBtn = document.getElementById("vote-change-btn");
+
+    if (!undoBtn || !changeBtn || !document.getElementById("vote-undo")) {
+        console.error("One or more vote undo elements not found. Ensure IDs are correct.");
+        return;
+    }
+
+    undoBtn.addEventListener("click", undoVote);
+    changeBtn.addEventListener("click", changeVote);
+});
+
+// Offer to undo or change vote for the undo window the server reported
+function offerUndo(vote) {
+    lastVote = vote;
+    clearTimeout(undoTimer);
+    document.getElementById("vote-undo").hidden = false;
+    undoTimer = setTimeout(hideUndo, vote.seconds * 1000);
+}
+
+function hideUndo() {
+    lastVote = null;
+    clearTimeout(undoTimer);
+    document.getElementById("vote-undo").hidden = true;
+}
+
+// Remove the vote and bring its image back so it can be voted on again
+async function undoVote() {
+    const vote = lastVote;
+    if (!vote) {
+        return;
+    }
+    hideUndo();
+    try {
+        const response = await fetch(`/votes/${vote.id}`, { method: "DELETE" });
+        const data = await response.json();
+        if (!response.ok) {
+            throw new Error(data.error || response.statusText);
+        }
+
+        currentImageUrl = vote.imageUrl;
+        currentImageId = vote.imageId;
+        document.getElementById("voting-image").src = vote.imageUrl;
+        displayVoteHistory();
+    } catch (error) {
+        console.error("Error undoing vote:", error);
+    }
+}
+
+// Turn a like into a dislike or the other way round
+async function changeVote() {
+    const vote = lastVote;
+    if (!vote) {
+        return;
+    }
+    const value = vote.action === "like" ? "dislike" : "like";
+    try {
+        const response = await fetch(`/votes/${vote.id}`, {
+            method: "PUT",
+            headers: {
+                "Content-Type": "application/x-www-form-urlencoded",
+            },
+            body: `value=${value}`
+        });
+        const data = await response.json();
+        if (!response.ok) {
+            throw new Error(data.error || response.statusText);
+        }
+
+        if (lastVote === vote) {
+            lastVote = { ...vote, id: data.id, action: value };
+        }
+        displayVoteHistory();
+    } catch (error) {
+        console.error("Error changing vote:", error);
+    }
+}
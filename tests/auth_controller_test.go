@@ -0,0 +1,126 @@
+package tests
+
+import (
+	stdcontext "context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	beego "github.com/beego/beego/v2/server/web"
+	"github.com/beego/beego/v2/server/web/context"
+	"github.com/beego/beego/v2/server/web/session"
+	"github.com/stretchr/testify/assert"
+
+	"myproject/controllers"
+	"myproject/models"
+)
+
+func init() {
+	manager, err := session.NewManager("memory", &session.ManagerConfig{
+		CookieName:      "catsession",
+		EnableSetCookie: true,
+		Gclifetime:      3600,
+	})
+	if err != nil {
+		panic(err)
+	}
+	beego.GlobalSessions = manager
+}
+
+// withSession starts a session for ctx, logged in as userID unless it is
+// empty, and returns the session store.
+func withSession(ctx *context.Context, userID string) session.Store {
+	store, err := beego.GlobalSessions.SessionStart(ctx.ResponseWriter, ctx.Request)
+	if err != nil {
+		panic(err)
+	}
+	if userID != "" {
+		store.Set(stdcontext.TODO(), "user_id", userID)
+	}
+	ctx.Input.CruSession = store
+	return store
+}
+
+func newAuthController(method, path string, users *models.UserStore) (*controllers.AuthController, *httptest.ResponseRecorder, session.Store) {
+	ctx, w := createTestContext(method, path)
+	store := withSession(ctx, "")
+	controller := &controllers.AuthController{Users: users}
+	controller.Init(ctx, "", "", controller)
+	return controller, w, store
+}
+
+func TestAuthController_RegisterAndLogin(t *testing.T) {
+	users, err := models.NewUserStore("")
+	assert.NoError(t, err)
+
+	controller, w, _ := newAuthController("POST", "/register?username=alice&password=whiskers1", users)
+	controller.Register()
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	var registered map[string]string
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &registered))
+	assert.Equal(t, "alice", registered["username"])
+	assert.NotEmpty(t, registered["id"])
+	assert.Equal(t, registered["id"], controller.CruSession.Get(stdcontext.TODO(), "user_id"))
+
+	controller, w, _ = newAuthController("POST", "/register?username=alice&password=whiskers2", users)
+	controller.Register()
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	controller, w, _ = newAuthController("POST", "/register?username=bob&password=short", users)
+	controller.Register()
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	controller, w, store := newAuthController("POST", "/login?username=alice&password=wrong-password", users)
+	controller.Login()
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Nil(t, store.Get(stdcontext.TODO(), "user_id"))
+
+	controller, w, _ = newAuthController("POST", "/login?username=alice&password=whiskers1", users)
+	controller.Login()
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, registered["id"], controller.CruSession.Get(stdcontext.TODO(), "user_id"))
+}
+
+func TestAuthController_MeAndLogout(t *testing.T) {
+	users, err := models.NewUserStore("")
+	assert.NoError(t, err)
+	user, err := users.Register("carol", "whiskers1")
+	assert.NoError(t, err)
+
+	controller, w, _ := newAuthController("GET", "/me", users)
+	controller.Me()
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	controller, w, store := newAuthController("GET", "/me", users)
+	store.Set(stdcontext.TODO(), "user_id", user.ID)
+	controller.Me()
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"username":"carol"`)
+
+	controller, w, store = newAuthController("POST", "/logout", users)
+	store.Set(stdcontext.TODO(), "user_id", user.ID)
+	controller.Logout()
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Nil(t, store.Get(stdcontext.TODO(), "user_id"))
+}
+
+func TestUserStore_Persists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "users.json")
+
+	users, err := models.NewUserStore(path)
+	assert.NoError(t, err)
+	registered, err := users.Register("dave", "whiskers1")
+	assert.NoError(t, err)
+
+	reloaded, err := models.NewUserStore(path)
+	assert.NoError(t, err)
+	user, err := reloaded.Authenticate("dave", "whiskers1")
+	assert.NoError(t, err)
+	assert.Equal(t, registered.ID, user.ID)
+
+	_, err = reloaded.Authenticate("dave", "whiskers2")
+	assert.ErrorIs(t, err, models.ErrInvalidCredentials)
+}
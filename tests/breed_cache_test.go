@@ -0,0 +1,191 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"myproject/catapi"
+	"myproject/fakecatapi"
+)
+
+// flakyUpstream wraps the fake Cat API, counting requests and failing
+// them with 503 while down is set.
+type flakyUpstream struct {
+	handler  http.Handler
+	requests atomic.Int32
+	down     atomic.Bool
+}
+
+func (u *flakyUpstream) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	u.requests.Add(1)
+	if u.down.Load() {
+		http.Error(w, "upstream down", http.StatusServiceUnavailable)
+		return
+	}
+	u.handler.ServeHTTP(w, r)
+}
+
+func TestBreedCache(t *testing.T) {
+	upstream := &flakyUpstream{handler: fakecatapi.New()}
+	server := httptest.NewServer(upstream)
+	defer server.Close()
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+	client := catapi.New("test-api-key",
+		catapi.WithBaseURL(server.URL+"/v1"),
+		catapi.WithRetry(catapi.RetryPolicy{MaxAttempts: 1}),
+	)
+	cache := catapi.NewBreedCache(client, time.Minute, catapi.WithCacheClock(clock))
+
+	// First lookup downloads the list
+	breeds, status, err := cache.Breeds(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, catapi.CacheMiss, status)
+	assert.Len(t, breeds, 10)
+
+	// Within the TTL nothing goes upstream, including single-breed lookups
+	breed, ok, status, err := cache.Breed(context.Background(), "siam")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "Siamese", breed.Name)
+	assert.Equal(t, catapi.CacheHit, status)
+	assert.Equal(t, int32(1), upstream.requests.Load())
+
+	// After the TTL the ETag is revalidated
+	now = now.Add(2 * time.Minute)
+	breeds, status, err = cache.Breeds(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, catapi.CacheRevalidated, status)
+	assert.Len(t, breeds, 10)
+	assert.Equal(t, int32(2), upstream.requests.Load())
+
+	// When the upstream fails the expired list is still served
+	upstream.down.Store(true)
+	now = now.Add(2 * time.Minute)
+	breeds, status, err = cache.Breeds(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, catapi.CacheStale, status)
+	assert.Len(t, breeds, 10)
+
+	_, ok, status, err = cache.Breed(context.Background(), "nope")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+	assert.Equal(t, catapi.CacheStale, status)
+	assert.Equal(t, int32(3), upstream.requests.Load())
+
+	stats := cache.Stats()
+	assert.Equal(t, int64(1), stats.Hits)
+	assert.Equal(t, int64(1), stats.Misses)
+	assert.Equal(t, int64(1), stats.Revalidated)
+	assert.Equal(t, int64(2), stats.Stale)
+	assert.Equal(t, 10, stats.Breeds)
+}
+
+func TestBreedCache_ErrorWithoutData(t *testing.T) {
+	upstream := &flakyUpstream{handler: fakecatapi.New()}
+	upstream.down.Store(true)
+	server := httptest.NewServer(upstream)
+	defer server.Close()
+
+	client := catapi.New("test-api-key",
+		catapi.WithBaseURL(server.URL+"/v1"),
+		catapi.WithRetry(catapi.RetryPolicy{MaxAttempts: 1}),
+	)
+	cache := catapi.NewBreedCache(client, time.Minute)
+
+	_, _, err := cache.Breeds(context.Background())
+	var apiErr *catapi.APIError
+	assert.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusServiceUnavailable, apiErr.StatusCode)
+	assert.Equal(t, int64(1), cache.Stats().Errors)
+}
+
+func TestBreedCache_SnapshotFallback(t *testing.T) {
+	upstream := &flakyUpstream{handler: fakecatapi.New()}
+	upstream.down.Store(true)
+	server := httptest.NewServer(upstream)
+	defer server.Close()
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	takenAt := now.Add(-30 * 24 * time.Hour)
+	client := catapi.New("test-api-key",
+		catapi.WithBaseURL(server.URL+"/v1"),
+		catapi.WithRetry(catapi.RetryPolicy{MaxAttempts: 1}),
+	)
+	cache := catapi.NewBreedCache(client, time.Minute,
+		catapi.WithCacheClock(func() time.Time { return now }),
+		catapi.WithSnapshot([]catapi.Breed{{ID: "abys", Name: "Abyssinian"}}, takenAt),
+	)
+
+	// Nothing downloaded yet, so the snapshot is served as stale
+	breeds, status, err := cache.Breeds(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, catapi.CacheStale, status)
+	assert.Len(t, breeds, 1)
+	stats := cache.Stats()
+	assert.True(t, stats.Snapshot)
+	assert.Equal(t, takenAt, stats.FetchedAt)
+	assert.Equal(t, int64(0), stats.Errors)
+
+	// The upstream is retried once the stale interval is over
+	upstream.down.Store(false)
+	now = now.Add(time.Minute)
+	breeds, status, err = cache.Breeds(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, catapi.CacheMiss, status)
+	assert.Len(t, breeds, 10)
+	stats = cache.Stats()
+	assert.False(t, stats.Snapshot)
+	assert.Equal(t, now, stats.FetchedAt)
+}
+
+func TestBreedCache_CallerCancelIsNotAnOutage(t *testing.T) {
+	server, requests, release := newBlockingServer(t, `[{"id": "abys", "name": "Abyssinian"}]`)
+	client := catapi.New("test-api-key",
+		catapi.WithBaseURL(server.URL),
+		catapi.WithRetry(catapi.RetryPolicy{MaxAttempts: 1}),
+	)
+	cache := catapi.NewBreedCache(client, time.Minute,
+		catapi.WithSnapshot([]catapi.Breed{{ID: "snap"}}, time.Now()),
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		_, _, err := cache.Breeds(ctx)
+		done <- err
+	}()
+	waitFor(t, func() bool { return requests.Load() == 1 })
+
+	// Stats does not wait for the upstream
+	stats := make(chan catapi.CacheStats, 1)
+	go func() { stats <- cache.Stats() }()
+	select {
+	case <-stats:
+	case <-time.After(time.Second):
+		t.Fatal("Stats blocked behind the upstream request")
+	}
+
+	// The caller leaving neither counts as an error nor loads the snapshot
+	cancel()
+	assert.ErrorIs(t, <-done, context.Canceled)
+	got := cache.Stats()
+	assert.Zero(t, got.Errors)
+	assert.Zero(t, got.Stale)
+	assert.False(t, got.Snapshot)
+
+	close(release)
+	breeds, status, err := cache.Breeds(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, catapi.CacheMiss, status)
+	if assert.Len(t, breeds, 1) {
+		assert.Equal(t, "abys", breeds[0].ID)
+	}
+}
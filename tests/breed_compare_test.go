@@ -0,0 +1,116 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	beecontext "github.com/beego/beego/v2/server/web/context"
+	"github.com/stretchr/testify/assert"
+
+	"myproject/breeds"
+	"myproject/catapi"
+	"myproject/controllers"
+)
+
+func TestCompareBreeds(t *testing.T) {
+	list := []catapi.Breed{
+		{ID: "a", LifeSpan: "10 - 12", Weight: catapi.Weight{Metric: "2 - 4"},
+			BreedRatings: catapi.BreedRatings{EnergyLevel: 5, SheddingLevel: 1, Intelligence: 3}},
+		{ID: "b", LifeSpan: "14 - 16", Weight: catapi.Weight{Metric: "4 - 8"},
+			BreedRatings: catapi.BreedRatings{EnergyLevel: 2, SheddingLevel: 4, Intelligence: 3}},
+		{ID: "c", LifeSpan: "unknown",
+			BreedRatings: catapi.BreedRatings{EnergyLevel: 5, SheddingLevel: 1}},
+	}
+
+	cmp := breeds.Compare(list)
+	rows := map[string]breeds.ComparisonRow{}
+	for _, row := range cmp.Rows {
+		rows[row.Trait] = row
+	}
+	assert.Len(t, cmp.Breeds, 3)
+	assert.NotContains(t, rows, "bidability", "traits nobody has are left out")
+
+	weight := rows["weight"]
+	assert.Equal(t, "kg", weight.Unit)
+	assert.Equal(t, 3.0, *weight.Values[0])
+	assert.Nil(t, weight.Values[2])
+	assert.Equal(t, 0.5, *weight.Normalized[0])
+	assert.Equal(t, 3.0, weight.Spread)
+	assert.Empty(t, weight.Winners)
+
+	lifeSpan := rows["life_span"]
+	assert.Equal(t, []string{"b"}, lifeSpan.Winners)
+	assert.Equal(t, 4.0, *lifeSpan.Differences[0])
+	assert.Equal(t, 0.0, *lifeSpan.Differences[1])
+	assert.Nil(t, lifeSpan.Differences[2])
+
+	energy := rows["energy_level"]
+	assert.Equal(t, breeds.HigherIsBetter, energy.Better)
+	assert.Equal(t, []string{"a", "c"}, energy.Winners)
+	assert.Equal(t, 1.0, *energy.Normalized[0])
+	assert.Equal(t, 0.25, *energy.Normalized[1])
+	assert.Equal(t, 3.0, *energy.Differences[1])
+
+	shedding := rows["shedding_level"]
+	assert.Equal(t, breeds.LowerIsBetter, shedding.Better)
+	assert.Equal(t, []string{"a", "c"}, shedding.Winners)
+
+	intelligence := rows["intelligence"]
+	assert.Empty(t, intelligence.Winners, "a tie has no winner")
+	assert.Equal(t, "", intelligence.Display[2])
+
+	assert.Equal(t, map[string]int{"a": 2, "b": 1, "c": 2}, cmp.Wins)
+}
+
+func TestBreedSearchController_Compare(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+		wantIDs    []string
+		wantError  string
+	}{
+		{"three breeds", "ids=abys,beng,siam", http.StatusOK, []string{"abys", "beng", "siam"}, ""},
+		{"duplicates collapse", "ids=abys,%20abys,sphy", http.StatusOK, []string{"abys", "sphy"}, ""},
+		{"one breed", "ids=abys", http.StatusBadRequest, nil, "ids must list 2 to 4 different breeds"},
+		{"five breeds", "ids=abys,beng,siam,sphy,pers", http.StatusBadRequest, nil, "ids must list 2 to 4 different breeds"},
+		{"unknown breed", "ids=abys,nope", http.StatusNotFound, nil, "Breed not found: nope"},
+	}
+
+	_, client := newFakeCatAPI(t)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			controller := &controllers.BreedSearchController{APIKey: "test-api-key", API: client}
+
+			r, _ := http.NewRequest("GET", "/breed-search/compare?"+tt.query, nil)
+			w := httptest.NewRecorder()
+			ctx := beecontext.NewContext()
+			ctx.Reset(w, r)
+			controller.Init(ctx, "", "", controller)
+
+			controller.Compare()
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantError != "" {
+				var response map[string]string
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+				assert.Equal(t, tt.wantError, response["error"])
+				return
+			}
+
+			var cmp breeds.Comparison
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &cmp))
+			ids := []string{}
+			for _, b := range cmp.Breeds {
+				ids = append(ids, b.ID)
+				assert.NotNil(t, b.Image)
+			}
+			assert.Equal(t, tt.wantIDs, ids)
+			for _, row := range cmp.Rows {
+				assert.Len(t, row.Values, len(ids), row.Trait)
+			}
+		})
+	}
+}
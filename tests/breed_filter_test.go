@@ -0,0 +1,179 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	beecontext "github.com/beego/beego/v2/server/web/context"
+	"github.com/stretchr/testify/assert"
+
+	"myproject/breeds"
+	"myproject/catapi"
+	"myproject/controllers"
+)
+
+func TestParseFilter(t *testing.T) {
+	tests := []struct {
+		name      string
+		query     string
+		want      breeds.Filter
+		wantError string
+	}{
+		{"empty", "", breeds.Filter{}, ""},
+		{"unrelated parameters are ignored", "page=2&breed_id=abys", breeds.Filter{}, ""},
+		{"origin and temperament", "origin=%20Egypt%20&temperament=Playful,%20,curious",
+			breeds.Filter{Origin: "Egypt", Temperament: []string{"Playful", "curious"}}, ""},
+		{"flags", "hairless=0&rare=true",
+			breeds.Filter{Flags: map[string]bool{"hairless": false, "rare": true}}, ""},
+		{"bounds", "min_energy_level=2&max_energy_level=4&max_grooming=1",
+			breeds.Filter{Min: map[string]int{"energy_level": 2}, Max: map[string]int{"energy_level": 4, "grooming": 1}}, ""},
+		{"sort", "sort=life_span", breeds.Filter{Sort: breeds.SortLifeSpan}, ""},
+		{"bound too low", "max_grooming=0", breeds.Filter{}, "max_grooming must be between 1 and 5"},
+		{"bound not a number", "min_intelligence=high", breeds.Filter{}, "min_intelligence must be between 1 and 5"},
+		{"bad flag", "indoor=yes", breeds.Filter{}, "indoor must be 0 or 1"},
+		{"bad sort", "sort=weight", breeds.Filter{}, "sort must be name, origin or life_span"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := url.ParseQuery(tt.query)
+			assert.NoError(t, err)
+
+			got, err := breeds.ParseFilter(q)
+			if tt.wantError != "" {
+				assert.EqualError(t, err, tt.wantError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestFilterApply(t *testing.T) {
+	list := []catapi.Breed{
+		{ID: "c", Name: "charlie", Origin: "Egypt", LifeSpan: "10 - 12", Temperament: "Playful, Calm",
+			BreedRatings: catapi.BreedRatings{EnergyLevel: 4}},
+		{ID: "a", Name: "Alpha", Origin: "france", LifeSpan: "14 - 16", Temperament: "Curious",
+			BreedRatings: catapi.BreedRatings{EnergyLevel: 2, Grooming: 3},
+			BreedFlags:   catapi.BreedFlags{Hairless: true}},
+		{ID: "b", Name: "bravo", Origin: "Egypt", LifeSpan: "unknown", Temperament: "playful",
+			BreedRatings: catapi.BreedRatings{EnergyLevel: 5, Grooming: 1}},
+	}
+	ids := func(list []catapi.Breed) []string {
+		out := []string{}
+		for _, b := range list {
+			out = append(out, b.ID)
+		}
+		return out
+	}
+
+	tests := []struct {
+		name   string
+		filter breeds.Filter
+		want   []string
+	}{
+		{"zero filter keeps upstream order", breeds.Filter{}, []string{"c", "a", "b"}},
+		{"origin ignores case", breeds.Filter{Origin: "FRANCE"}, []string{"a"}},
+		{"temperament ignores case", breeds.Filter{Temperament: []string{"PLAYFUL"}}, []string{"c", "b"}},
+		{"every temperament must match", breeds.Filter{Temperament: []string{"playful", "calm"}}, []string{"c"}},
+		{"flag set", breeds.Filter{Flags: map[string]bool{"hairless": true}}, []string{"a"}},
+		{"flag unset", breeds.Filter{Flags: map[string]bool{"hairless": false}}, []string{"c", "b"}},
+		{"minimum", breeds.Filter{Min: map[string]int{"energy_level": 4}}, []string{"c", "b"}},
+		{"maximum skips unrated breeds", breeds.Filter{Max: map[string]int{"grooming": 3}}, []string{"a", "b"}},
+		{"sort by name ignores case", breeds.Filter{Sort: breeds.SortName}, []string{"a", "b", "c"}},
+		{"sort by origin then name", breeds.Filter{Sort: breeds.SortOrigin}, []string{"b", "c", "a"}},
+		{"sort by life span, unknown last", breeds.Filter{Sort: breeds.SortLifeSpan}, []string{"a", "c", "b"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ids(tt.filter.Apply(list)))
+		})
+	}
+
+	// The input is left in upstream order
+	assert.Equal(t, []string{"c", "a", "b"}, ids(list))
+}
+
+func TestBreedSearchController_GetFiltered(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+		wantIDs    []string
+		wantError  string
+	}{
+		{"origin ignores case", "origin=united%20states&sort=name", http.StatusOK, []string{"beng", "mcoo", "ragd"}, ""},
+		{"flag and minimum", "hypoallergenic=1&min_energy_level=5&sort=name", http.StatusOK, []string{"beng", "siam", "sibe"}, ""},
+		{"maximum", "max_shedding_level=2", http.StatusOK, []string{"abys", "siam", "sphy"}, ""},
+		{"every temperament must match", "temperament=playful,Curious", http.StatusOK, []string{"bure", "sibe"}, ""},
+		{"sort by life span", "sort=life_span", http.StatusOK,
+			[]string{"bure", "abys", "pers", "ragd", "beng", "mcoo", "siam", "sibe", "sphy", "aege"}, ""},
+		{"sort by origin", "origin=Russia&sort=origin", http.StatusOK, []string{"sibe"}, ""},
+		{"no match", "origin=Atlantis", http.StatusOK, []string{}, ""},
+		{"level out of range", "min_energy_level=9", http.StatusBadRequest, nil, "min_energy_level must be between 1 and 5"},
+		{"bad flag", "hairless=maybe", http.StatusBadRequest, nil, "hairless must be 0 or 1"},
+		{"unknown sort", "sort=age", http.StatusBadRequest, nil, "sort must be name, origin or life_span"},
+	}
+
+	_, client := newFakeCatAPI(t)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			controller := &controllers.BreedSearchController{APIKey: "test-api-key", API: client}
+
+			r, _ := http.NewRequest("GET", "/breed-search?"+tt.query, nil)
+			w := httptest.NewRecorder()
+			ctx := beecontext.NewContext()
+			ctx.Reset(w, r)
+			controller.Init(ctx, "", "", controller)
+
+			controller.Get()
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantError != "" {
+				var response map[string]string
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+				assert.Equal(t, tt.wantError, response["error"])
+				return
+			}
+
+			var breeds []controllers.CatBreed
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &breeds))
+			ids := []string{}
+			for _, b := range breeds {
+				ids = append(ids, b.ID)
+			}
+			assert.Equal(t, tt.wantIDs, ids)
+		})
+	}
+}
+
+func TestBreedSearchController_GetUpstreamError(t *testing.T) {
+	withConfig(t, "breed_snapshot_fallback", "false")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "boom", http.StatusInternalServerError)
+	}))
+	defer server.Close()
+	client := catapi.New("test-api-key",
+		catapi.WithBaseURL(server.URL),
+		catapi.WithRetry(catapi.RetryPolicy{MaxAttempts: 1}),
+	)
+	controller := &controllers.BreedSearchController{APIKey: "test-api-key", API: client}
+
+	r, _ := http.NewRequest("GET", "/breed-search", nil)
+	w := httptest.NewRecorder()
+	ctx := beecontext.NewContext()
+	ctx.Reset(w, r)
+	controller.Init(ctx, "", "", controller)
+
+	controller.Get()
+
+	assert.Equal(t, http.StatusBadGateway, w.Code)
+	var response map[string]string
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "Failed to fetch breed list", response["error"])
+}
@@ -0,0 +1,154 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/beego/beego/v2/server/web/session"
+	"github.com/stretchr/testify/assert"
+
+	"myproject/catapi"
+	"myproject/controllers"
+)
+
+type galleryResponse struct {
+	Images     []controllers.BreedImage `json:"images"`
+	Pagination controllers.GalleryPage  `json:"pagination"`
+	Error      string                   `json:"error"`
+}
+
+// getGallery calls BreedSearchController.Images, in store's session when
+// store is not nil
+func getGallery(t *testing.T, client *catapi.Client, query string, store session.Store) (int, galleryResponse) {
+	ctx, w := createTestContext("GET", "/breed-search/images?"+query)
+	if store != nil {
+		ctx.Input.CruSession = store
+	}
+	controller := &controllers.BreedSearchController{APIKey: "test-api-key", API: client}
+	controller.Init(ctx, "", "", controller)
+
+	controller.Images()
+
+	var response galleryResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	return w.Code, response
+}
+
+func TestBreedSearchController_ImagesPaging(t *testing.T) {
+	_, client := newFakeCatAPI(t)
+
+	var ids []string
+	for page, want := range []struct {
+		count   int
+		hasMore bool
+	}{{5, true}, {5, true}, {2, false}} {
+		status, response := getGallery(t, client, "breed_id=abys&order=asc&limit=5&page="+strconv.Itoa(page), nil)
+		assert.Equal(t, http.StatusOK, status)
+		assert.Len(t, response.Images, want.count)
+		assert.Equal(t, controllers.GalleryPage{
+			Page: page, Limit: 5, Order: "ASC", Count: 12, HasMore: want.hasMore,
+		}, response.Pagination)
+		for _, img := range response.Images {
+			ids = append(ids, img.ID)
+		}
+	}
+	assert.Equal(t, "abys-1", ids[0])
+	assert.Len(t, ids, 12)
+
+	status, response := getGallery(t, client, "breed_id=abys&order=DESC&limit=25&mime_types=png", nil)
+	assert.Equal(t, http.StatusOK, status)
+	assert.NotEmpty(t, response.Images)
+	for _, img := range response.Images {
+		assert.True(t, strings.HasSuffix(img.URL, ".png"), img.URL)
+	}
+
+	// Random order has no count, so a full page means there may be more
+	status, response = getGallery(t, client, "breed_id=abys", nil)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Len(t, response.Images, 8)
+	assert.Equal(t, "RAND", response.Pagination.Order)
+	assert.True(t, response.Pagination.HasMore)
+}
+
+func TestBreedSearchController_ImagesInvalid(t *testing.T) {
+	tests := []struct {
+		query     string
+		wantError string
+	}{
+		{"", "Breed ID is required"},
+		{"breed_id=abys&page=-1", "page must be a non-negative integer"},
+		{"breed_id=abys&limit=0", "limit must be between 1 and 25"},
+		{"breed_id=abys&limit=26", "limit must be between 1 and 25"},
+		{"breed_id=abys&order=sideways", "order must be RAND, ASC or DESC"},
+		{"breed_id=abys&mime_types=jpg,bmp", "mime_types may only list jpg, png and gif"},
+	}
+
+	_, client := newFakeCatAPI(t)
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			status, response := getGallery(t, client, tt.query, nil)
+			assert.Equal(t, http.StatusBadRequest, status)
+			assert.Equal(t, tt.wantError, response.Error)
+		})
+	}
+}
+
+func TestBreedSearchController_ImagesDedup(t *testing.T) {
+	// Pages that overlap, as random pages or a shifting upstream do
+	pages := map[string]string{
+		"0": `[{"id": "a", "url": "https://example.com/a.jpg"}, {"id": "b", "url": "https://example.com/b.jpg"}, {"id": "a", "url": "https://example.com/a.jpg"}]`,
+		"1": `[{"id": "b", "url": "https://example.com/b.jpg"}, {"id": "c", "url": "https://example.com/c.jpg"}, {"id": "a", "url": "https://example.com/a.jpg"}]`,
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page := r.URL.Query().Get("page")
+		if page == "" {
+			page = "0"
+		}
+		w.Write([]byte(pages[page]))
+	}))
+	defer server.Close()
+	client := catapi.New("test-api-key", catapi.WithBaseURL(server.URL+"/v1"))
+
+	ctx, _ := createTestContext("GET", "/breed-search/images")
+	store := withSession(ctx, "")
+
+	imageIDs := func(images []controllers.BreedImage) []string {
+		ids := []string{}
+		for _, img := range images {
+			ids = append(ids, img.ID)
+		}
+		return ids
+	}
+
+	_, response := getGallery(t, client, "breed_id=abys&limit=3", store)
+	assert.Equal(t, []string{"a", "b"}, imageIDs(response.Images))
+	assert.Equal(t, 1, response.Pagination.Duplicates)
+
+	_, response = getGallery(t, client, "breed_id=abys&limit=3&page=1", store)
+	assert.Equal(t, []string{"c"}, imageIDs(response.Images))
+	assert.Equal(t, 2, response.Pagination.Duplicates)
+
+	// Another gallery of the same breed is tracked separately
+	_, response = getGallery(t, client, "breed_id=abys&limit=3&page=1&order=ASC", store)
+	assert.Equal(t, []string{"b", "c", "a"}, imageIDs(response.Images))
+
+	// and replaces the first, so the session holds one gallery at most
+	_, response = getGallery(t, client, "breed_id=abys&limit=3&page=1", store)
+	assert.Equal(t, []string{"b", "c", "a"}, imageIDs(response.Images))
+	galleries := 0
+	for _, key := range []string{"gallery", "gallery:abys|RAND|", "gallery:abys|ASC|"} {
+		if store.Get(context.Background(), key) != nil {
+			galleries++
+		}
+	}
+	assert.Equal(t, 1, galleries)
+
+	// Going back to the first page starts over
+	_, response = getGallery(t, client, "breed_id=abys&limit=3", store)
+	assert.Equal(t, []string{"a", "b"}, imageIDs(response.Images))
+}
@@ -0,0 +1,155 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"myproject/breeds"
+	"myproject/catapi"
+	"myproject/controllers"
+)
+
+func TestRecommendBreeds(t *testing.T) {
+	list := []catapi.Breed{
+		{ID: "a", Name: "A",
+			BreedRatings: catapi.BreedRatings{SheddingLevel: 1, EnergyLevel: 5, ChildFriendly: 2},
+			BreedFlags:   catapi.BreedFlags{Hypoallergenic: true}},
+		{ID: "b", Name: "B",
+			BreedRatings: catapi.BreedRatings{SheddingLevel: 4, EnergyLevel: 2, ChildFriendly: 5}},
+	}
+	yes := true
+	answers := breeds.Answers{Allergies: &yes, Kids: &yes}
+
+	matches := breeds.Recommend(list, answers, breeds.DefaultWeights, 0)
+	if assert.Len(t, matches, 2) {
+		assert.Equal(t, "a", matches[0].ID)
+		assert.Equal(t, 70.0, matches[0].Score)
+		assert.Equal(t, 47.5, matches[1].Score)
+
+		top := matches[0].Breakdown
+		assert.Len(t, top, 3)
+		assert.Equal(t, "allergies", top[0].Question)
+		assert.Equal(t, 30.0, top[0].Points)
+		assert.Equal(t, "child_friendly", top[2].Trait)
+		assert.Equal(t, 0.25, top[2].Fit)
+		assert.Equal(t, 10.0, top[2].Points)
+	}
+
+	// Ignoring allergies leaves only the kids question
+	weights := breeds.Weights{"allergies": 0, "kids": 1}
+	matches = breeds.Recommend(list, answers, weights, 1)
+	if assert.Len(t, matches, 1) {
+		assert.Equal(t, "b", matches[0].ID)
+		assert.Equal(t, 100.0, matches[0].Score)
+	}
+
+	// Unrated traits don't count against a breed
+	matches = breeds.Recommend(list, breeds.Answers{OtherPets: []string{"cats"}}, breeds.DefaultWeights, 0)
+	for _, m := range matches {
+		assert.Equal(t, 0.0, m.Score)
+		assert.Empty(t, m.Breakdown)
+	}
+}
+
+// A question counts by its weight however many traits it asks about
+func TestRecommendBreeds_WeighsQuestionsNotTraits(t *testing.T) {
+	list := []catapi.Breed{
+		// Ideal for a small apartment, poor with children
+		{ID: "flat", Name: "Flat",
+			BreedRatings: catapi.BreedRatings{EnergyLevel: 2, Adaptability: 5, Vocalisation: 1, ChildFriendly: 1}},
+		// Great with children, as poor a fit for a small apartment as
+		// the ratings allow
+		{ID: "family", Name: "Family",
+			BreedRatings: catapi.BreedRatings{EnergyLevel: 5, Adaptability: 1, Vocalisation: 5, ChildFriendly: 5}},
+	}
+	yes := true
+	answers := breeds.Answers{ApartmentSize: "small", Kids: &yes}
+
+	matches := breeds.Recommend(list, answers, breeds.DefaultWeights, 0)
+	if assert.Len(t, matches, 2) {
+		// kids weighs 2 and apartment_size 1, despite its three traits
+		assert.Equal(t, "family", matches[0].ID)
+		assert.Equal(t, 69.44, matches[0].Score)
+		assert.Equal(t, 33.33, matches[1].Score)
+
+		byQuestion := map[string]float64{}
+		for _, item := range matches[1].Breakdown {
+			byQuestion[item.Question] += item.Weight
+		}
+		assert.InDelta(t, 1.0, byQuestion["apartment_size"], 0.02)
+		assert.Equal(t, 2.0, byQuestion["kids"])
+	}
+
+	// With equal weights each question is worth half the score
+	matches = breeds.Recommend(list, answers, breeds.Weights{"apartment_size": 1, "kids": 1}, 0)
+	if assert.Len(t, matches, 2) {
+		assert.Equal(t, 54.17, matches[0].Score)
+		assert.Equal(t, 50.0, matches[1].Score)
+	}
+}
+
+func TestAnswersValidate(t *testing.T) {
+	yes, no := true, false
+	assert.NoError(t, breeds.Answers{Kids: &yes}.Validate())
+	assert.NoError(t, breeds.Answers{Kids: &no, TimeAtHome: "lots"}.Validate())
+	assert.EqualError(t, breeds.Answers{}.Validate(), "answer at least one question")
+
+	// Answers without any criteria would score every breed 0
+	err := breeds.Answers{Allergies: &no, Kids: &no}.Validate()
+	assert.EqualError(t, err, "answer apartment_size, time_at_home or other_pets, or yes to allergies or kids")
+}
+
+func TestBreedSearchController_Match(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+		wantError  string
+	}{
+		{"allergic family", "allergies=true&kids=1&apartment_size=small&other_pets=dogs&limit=3", http.StatusOK, ""},
+		{"no answers", "", http.StatusBadRequest, "answer at least one question"},
+		{"only negative answers", "allergies=false&kids=0", http.StatusBadRequest,
+			"answer apartment_size, time_at_home or other_pets, or yes to allergies or kids"},
+		{"bad size", "apartment_size=huge", http.StatusBadRequest, "apartment_size must be one of small, medium, large"},
+		{"bad time", "time_at_home=never", http.StatusBadRequest, "time_at_home must be one of little, some, lots"},
+		{"bad pet", "other_pets=dogs,parrots", http.StatusBadRequest, "other_pets may only list dogs, cats"},
+		{"bad flag", "kids=maybe", http.StatusBadRequest, "kids must be true or false"},
+		{"bad limit", "kids=true&limit=50", http.StatusBadRequest, "limit must be between 1 and 20"},
+	}
+
+	_, client := newFakeCatAPI(t)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, w := createTestContext("POST", "/breed-search/match?"+tt.query)
+			controller := &controllers.BreedSearchController{APIKey: "test-api-key", API: client}
+			controller.Init(ctx, "", "", controller)
+
+			controller.Match()
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			var response struct {
+				Matches []breeds.Match `json:"matches"`
+				Error   string         `json:"error"`
+			}
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, tt.wantError, response.Error)
+			if tt.wantError != "" {
+				return
+			}
+
+			assert.Len(t, response.Matches, 3)
+			hypoallergenic := map[string]bool{"beng": true, "bure": true, "sibe": true, "siam": true, "sphy": true}
+			for i, m := range response.Matches {
+				assert.True(t, hypoallergenic[m.ID], m.ID)
+				assert.NotEmpty(t, m.Breakdown)
+				assert.LessOrEqual(t, m.Score, 100.0)
+				if i > 0 {
+					assert.GreaterOrEqual(t, response.Matches[i-1].Score, m.Score)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"myproject/breeds"
+	"myproject/catapi"
+	"myproject/controllers"
+	"myproject/fakecatapi"
+)
+
+func TestByOrigin(t *testing.T) {
+	img := &catapi.Image{ID: "b-1", URL: "https://example.com/b.jpg"}
+	origins := breeds.ByOrigin([]catapi.Breed{
+		{ID: "c", Name: "Cymric", Origin: "Canada", CountryCode: "CA"},
+		{ID: "b", Name: "Bombay", Origin: "United States", CountryCode: "US", Image: img},
+		{ID: "a", Name: "American Curl", Origin: "United States", CountryCode: "us"},
+		{ID: "x", Name: "Mystery", Origin: "atlantis "},
+		{ID: "y", Name: "Another", Origin: "Atlantis"},
+	})
+
+	assert.Equal(t, []breeds.Origin{
+		{Country: "Atlantis", Count: 2,
+			Breeds: []breeds.OriginBreed{{ID: "y", Name: "Another"}, {ID: "x", Name: "Mystery"}}},
+		{Country: "United States", CountryCode: "US", Count: 2, Image: img,
+			Breeds: []breeds.OriginBreed{{ID: "a", Name: "American Curl"}, {ID: "b", Name: "Bombay"}}},
+		{Country: "Canada", CountryCode: "CA", Count: 1,
+			Breeds: []breeds.OriginBreed{{ID: "c", Name: "Cymric"}}},
+	}, origins)
+}
+
+func TestBreedSearchController_Origins(t *testing.T) {
+	upstream := &flakyUpstream{handler: fakecatapi.New()}
+	server := httptest.NewServer(upstream)
+	defer server.Close()
+	client := catapi.New("test-api-key", catapi.WithBaseURL(server.URL+"/v1"))
+
+	for range 2 {
+		ctx, w := createTestContext("GET", "/breed-search/origins")
+		controller := &controllers.BreedSearchController{APIKey: "test-api-key", API: client}
+		controller.Init(ctx, "", "", controller)
+
+		controller.Origins()
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var origins []breeds.Origin
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &origins))
+		if assert.Len(t, origins, 8) {
+			us := origins[0]
+			assert.Equal(t, "United States", us.Country)
+			assert.Equal(t, "US", us.CountryCode)
+			assert.Equal(t, 3, us.Count)
+			assert.Equal(t, []breeds.OriginBreed{
+				{ID: "beng", Name: "Bengal"}, {ID: "mcoo", Name: "Maine Coon"}, {ID: "ragd", Name: "Ragdoll"},
+			}, us.Breeds)
+			if assert.NotNil(t, us.Image) {
+				assert.Equal(t, "beng-1", us.Image.ID)
+			}
+			assert.Equal(t, "Burma", origins[1].Country)
+		}
+	}
+
+	// One breed list fetch serves every country, and the cache the rest
+	assert.Equal(t, int32(1), upstream.requests.Load())
+}
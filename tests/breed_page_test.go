@@ -0,0 +1,91 @@
+package tests
+
+import (
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	beego "github.com/beego/beego/v2/server/web"
+	"github.com/stretchr/testify/assert"
+
+	"myproject/controllers"
+)
+
+func renderBreedPage(t *testing.T, id, query string) (*controllers.BreedPageController, string) {
+	_, client := newFakeCatAPI(t)
+
+	viewsPath, _ := filepath.Abs("../views")
+	previous := beego.BConfig.WebConfig.ViewsPath
+	beego.BConfig.WebConfig.ViewsPath = viewsPath
+	t.Cleanup(func() { beego.BConfig.WebConfig.ViewsPath = previous })
+	assert.NoError(t, beego.AddViewPath(viewsPath))
+
+	ctx, _ := createTestContext("GET", "/breeds/"+id+"?"+query)
+	// A forged Host header must not leak into shared URLs
+	ctx.Request.Host = "evil.example.com"
+	ctx.Input.SetParam(":id", id)
+	controller := &controllers.BreedPageController{APIKey: "test-api-key", API: client}
+	controller.Init(ctx, "", "", controller)
+
+	controller.Get()
+	html, err := controller.RenderString()
+	assert.NoError(t, err)
+	return controller, html
+}
+
+func TestBreedPageController_Get(t *testing.T) {
+	withConfig(t, "site_url", "https://cats.example.com/")
+	controller, html := renderBreedPage(t, "sphy", "")
+
+	assert.Equal(t, "breed.tpl", controller.TplName)
+	assert.Contains(t, html, "<title>Sphynx - Cat Browser</title>")
+	assert.Contains(t, html, `<meta property="og:title" content="Sphynx">`)
+	assert.Contains(t, html, `<meta property="og:url" content="https://cats.example.com/breeds/sphy">`)
+	assert.Contains(t, html, `<link rel="canonical" href="https://cats.example.com/breeds/sphy">`)
+	assert.NotContains(t, html, "evil.example.com")
+	assert.Regexp(t, `<meta property="og:image" content="http://[^"]+/images/sphy-1\.\w+">`, html)
+	assert.Contains(t, html, `<meta property="og:description" content="`)
+	assert.Contains(t, html, "<dt>Life span</dt><dd>12 - 14 years</dd>")
+	assert.Contains(t, html, "<li>Hypoallergenic</li>")
+	assert.Contains(t, html, `<span class="rating-value">5/5</span>`)
+	assert.Contains(t, html, `class="wiki-link"`)
+	assert.Equal(t, 12, strings.Count(html, `loading="lazy"`))
+}
+
+func TestBreedPageController_Localized(t *testing.T) {
+	_, html := renderBreedPage(t, "sphy", "lang=de")
+
+	assert.Contains(t, html, `<html lang="de">`)
+	assert.Contains(t, html, "<title>Sphynx - Katzen-Browser</title>")
+	assert.Contains(t, html, "<dt>Lebenserwartung</dt><dd>12 - 14 Jahre</dd>")
+	assert.Contains(t, html, "<dt>Herkunft</dt>")
+	assert.Contains(t, html, "<li>Hypoallergen</li>")
+	assert.Contains(t, html, `<span class="rating-label">Anhänglichkeit</span>`)
+	assert.Contains(t, html, "<h2>Galerie</h2>")
+	for _, english := range []string{"Life span", "Origin", "Gallery", "Affection", "Lap cat"} {
+		assert.NotContains(t, html, english)
+	}
+}
+
+func TestBreedPageController_WithoutSiteURL(t *testing.T) {
+	withConfig(t, "site_url", "")
+	_, html := renderBreedPage(t, "sphy", "")
+
+	assert.NotContains(t, html, `rel="canonical"`)
+	assert.NotContains(t, html, `og:url`)
+	assert.NotContains(t, html, "evil.example.com")
+}
+
+func TestBreedPageController_NotFound(t *testing.T) {
+	_, client := newFakeCatAPI(t)
+
+	ctx, w := createTestContext("GET", "/breeds/nope")
+	ctx.Input.SetParam(":id", "nope")
+	controller := &controllers.BreedPageController{APIKey: "test-api-key", API: client}
+	controller.Init(ctx, "", "", controller)
+
+	assert.Panics(t, controller.Get)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, "Breed not found", w.Body.String())
+}
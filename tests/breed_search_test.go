@@ -5,11 +5,14 @@ import (
 	"github.com/beego/beego/v2/server/web/context"
 	"github.com/jarcoal/httpmock"
 	"github.com/stretchr/testify/assert"
+	"myproject/catapi"
 	"myproject/controllers"
 	"net/http"
 	"net/http/httptest"
 	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
 func TestBreedSearchController_Get(t *testing.T) {
@@ -131,3 +134,129 @@ func TestBreedSearchController_Post(t *testing.T) {
 	assert.Equal(t, "https://example.com/cat1.jpg", response.Images[0].URL)
 	assert.Equal(t, "https://example.com/cat2.jpg", response.Images[1].URL)
 }
+
+// newBreedSearchUpstream serves one breed and answers image searches with
+// imagesStatus, or holds them until the caller gives up when imagesStatus
+// is 0. inflight counts image searches still being handled.
+func newBreedSearchUpstream(t *testing.T, breedsStatus, imagesStatus int) (*httptest.Server, *atomic.Int32) {
+	var inflight atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/breeds":
+			if breedsStatus != http.StatusOK {
+				w.WriteHeader(breedsStatus)
+				return
+			}
+			w.Write([]byte(`[{"id": "abys", "name": "Abyssinian", "origin": "Egypt"}]`))
+		case "/v1/images/search":
+			inflight.Add(1)
+			defer inflight.Add(-1)
+			if imagesStatus == 0 {
+				<-r.Context().Done()
+				return
+			}
+			w.WriteHeader(imagesStatus)
+			w.Write([]byte(`[{"url": "https://example.com/cat1.jpg"}]`))
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server, &inflight
+}
+
+func TestBreedSearchController_PostErrors(t *testing.T) {
+	tests := []struct {
+		name         string
+		breedID      string
+		breedsStatus int
+		imagesStatus int
+		wantStatus   int
+		wantError    string
+	}{
+		{"missing breed id", "", http.StatusOK, http.StatusOK, http.StatusBadRequest, "Breed ID is required"},
+		{"unknown breed cancels image search", "nope", http.StatusOK, 0, http.StatusNotFound, "Breed not found"},
+		{"breed list fails", "abys", http.StatusInternalServerError, 0, http.StatusBadGateway, "Failed to fetch breed details"},
+		{"image search fails", "abys", http.StatusOK, http.StatusInternalServerError, http.StatusBadGateway, "Failed to fetch breed details"},
+	}
+
+	// Failures must reach the client rather than the breed snapshot
+	withConfig(t, "breed_snapshot_fallback", "false")
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, inflight := newBreedSearchUpstream(t, tt.breedsStatus, tt.imagesStatus)
+			client := catapi.New("test-api-key",
+				catapi.WithBaseURL(server.URL+"/v1"),
+				catapi.WithRetry(catapi.RetryPolicy{MaxAttempts: 1}),
+			)
+			controller := &controllers.BreedSearchController{APIKey: "test-api-key", API: client}
+
+			r, _ := http.NewRequest("POST", "/breed-search?breed_id="+tt.breedID, nil)
+			w := httptest.NewRecorder()
+			ctx := context.NewContext()
+			ctx.Reset(w, r)
+			controller.Init(ctx, "", "", controller)
+
+			done := make(chan struct{})
+			go func() {
+				controller.Post()
+				close(done)
+			}()
+			select {
+			case <-done:
+			case <-time.After(5 * time.Second):
+				t.Fatal("Post did not return")
+			}
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			var response map[string]string
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, tt.wantError, response["error"])
+
+			// The image search must not outlive the request
+			waitFor(t, func() bool { return inflight.Load() == 0 })
+		})
+	}
+}
+
+func TestBreedSearchController_PostFullBreedProfile(t *testing.T) {
+	_, client := newFakeCatAPI(t)
+
+	controller := &controllers.BreedSearchController{APIKey: "test-api-key", API: client}
+
+	r, _ := http.NewRequest("POST", "/breed-search?breed_id=sphy", nil)
+	w := httptest.NewRecorder()
+	ctx := context.NewContext()
+	ctx.Reset(w, r)
+	controller.Init(ctx, "", "", controller)
+
+	controller.Post()
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response struct {
+		Breed controllers.CatBreed `json:"breed"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+
+	breed := response.Breed
+	assert.Equal(t, "Sphynx", breed.Name)
+	assert.Contains(t, breed.Temperament, "Loyal")
+	assert.Equal(t, "12 - 14", breed.LifeSpan)
+	assert.Equal(t, "3 - 5", breed.Weight.Metric)
+	assert.Equal(t, 5, breed.AffectionLevel)
+	assert.Equal(t, 3, breed.EnergyLevel)
+	assert.True(t, bool(breed.Hypoallergenic))
+	assert.True(t, bool(breed.Hairless))
+	assert.False(t, bool(breed.Natural))
+	assert.Equal(t, "sphy-1", breed.ReferenceImageID)
+	if assert.NotNil(t, breed.Image) {
+		assert.Equal(t, "sphy-1", breed.Image.ID)
+		assert.NotEmpty(t, breed.Image.URL)
+	}
+
+	// Flags go out as booleans rather than the upstream's 0 and 1
+	var raw struct {
+		Breed map[string]interface{} `json:"breed"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &raw))
+	assert.Equal(t, true, raw.Breed["hypoallergenic"])
+}
@@ -0,0 +1,162 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	beecontext "github.com/beego/beego/v2/server/web/context"
+	"github.com/stretchr/testify/assert"
+
+	"myproject/breeds"
+	"myproject/catapi"
+	"myproject/controllers"
+)
+
+func TestBreedIndex_Search(t *testing.T) {
+	_, client := newFakeCatAPI(t)
+	list, err := client.ListBreeds(context.Background())
+	assert.NoError(t, err)
+	index := breeds.NewIndex(list)
+	assert.Equal(t, 10, index.Len())
+
+	tests := []struct {
+		query     string
+		wantFirst string
+		wantIDs   []string
+		wantField string
+	}{
+		{query: "siam", wantFirst: "siam"},
+		{query: "Siamse", wantFirst: "siam", wantField: "name"},
+		{query: "bengl", wantFirst: "beng", wantField: "name"},
+		{query: "main coon", wantFirst: "mcoo", wantField: "name"},
+		{query: "mai", wantFirst: "mcoo", wantField: "name"},
+		{query: "russia", wantIDs: []string{"sibe"}, wantField: "origin"},
+		{query: "playful", wantIDs: []string{"aege", "bure", "sibe"}, wantField: "temperament"},
+		{query: "playful curious", wantIDs: []string{"bure", "sibe"}},
+		{query: "xyzzy", wantIDs: []string{}},
+		{query: "  ", wantIDs: []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			got := index.Search(tt.query, 0)
+			ids := []string{}
+			for _, s := range got {
+				ids = append(ids, s.ID)
+			}
+			if tt.wantIDs != nil {
+				assert.Equal(t, tt.wantIDs, ids)
+			}
+			if tt.wantFirst != "" && assert.NotEmpty(t, got) {
+				assert.Equal(t, tt.wantFirst, got[0].ID)
+			}
+			if tt.wantField != "" && assert.NotEmpty(t, got) {
+				assert.Equal(t, tt.wantField, got[0].Field)
+			}
+			for i := 1; i < len(got); i++ {
+				assert.GreaterOrEqual(t, got[i-1].Score, got[i].Score)
+			}
+		})
+	}
+
+	assert.Len(t, index.Search("a", 3), 3)
+}
+
+func TestBreedIndexer_Rebuild(t *testing.T) {
+	calls := 0
+	var fail bool
+	status := catapi.CacheMiss
+	source := func(context.Context) ([]catapi.Breed, catapi.CacheStatus, error) {
+		calls++
+		if fail {
+			return nil, "", errors.New("upstream down")
+		}
+		return []catapi.Breed{{ID: "abys", Name: "Abyssinian"}}, status, nil
+	}
+
+	indexer := breeds.NewIndexer(source, 0)
+	index, got, err := indexer.Index(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, index.Len())
+	assert.Equal(t, catapi.CacheMiss, got)
+
+	// A failed rebuild keeps serving the last index, as stale
+	fail = true
+	index, got, err = indexer.Index(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, index.Len())
+	assert.Equal(t, catapi.CacheStale, got)
+	assert.Equal(t, 2, calls)
+
+	indexer = breeds.NewIndexer(source, 0)
+	_, _, err = indexer.Index(context.Background())
+	assert.Error(t, err)
+
+	// Within the refresh interval the index is reused
+	fail = false
+	indexer = breeds.NewIndexer(source, 1<<62)
+	for range 3 {
+		_, _, err = indexer.Index(context.Background())
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, 4, calls)
+
+	// unless it was built from stale data
+	status = catapi.CacheStale
+	indexer = breeds.NewIndexer(source, 1<<62)
+	for range 3 {
+		_, got, err = indexer.Index(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, catapi.CacheStale, got)
+	}
+	assert.Equal(t, 7, calls)
+}
+
+func TestBreedSearchController_Suggest(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+		wantFirst  string
+		wantError  string
+	}{
+		{"typo", "q=persain", http.StatusOK, "pers", ""},
+		{"limit", "q=a&limit=2", http.StatusOK, "", ""},
+		{"missing query", "", http.StatusBadRequest, "", "q is required"},
+		{"bad limit", "q=a&limit=100", http.StatusBadRequest, "", "limit must be between 1 and 25"},
+	}
+
+	_, client := newFakeCatAPI(t)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			controller := &controllers.BreedSearchController{APIKey: "test-api-key", API: client}
+
+			r, _ := http.NewRequest("GET", "/breed-search/suggest?"+tt.query, nil)
+			w := httptest.NewRecorder()
+			ctx := beecontext.NewContext()
+			ctx.Reset(w, r)
+			controller.Init(ctx, "", "", controller)
+
+			controller.Suggest()
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantError != "" {
+				var response map[string]string
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+				assert.Equal(t, tt.wantError, response["error"])
+				return
+			}
+
+			var suggestions []breeds.Suggestion
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &suggestions))
+			assert.LessOrEqual(t, len(suggestions), 10)
+			if tt.wantFirst != "" && assert.NotEmpty(t, suggestions) {
+				assert.Equal(t, tt.wantFirst, suggestions[0].ID)
+			}
+		})
+	}
+}
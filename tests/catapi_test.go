@@ -0,0 +1,231 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"myproject/catapi"
+)
+
+func TestCatAPIClient_ListBreeds(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/breeds", r.URL.Path)
+		assert.Equal(t, "test-api-key", r.Header.Get("x-api-key"))
+		w.Write([]byte(`[{"id": "abys", "name": "Abyssinian", "origin": "Egypt"}]`))
+	}))
+	defer server.Close()
+
+	client := catapi.New("test-api-key", catapi.WithBaseURL(server.URL+"/v1"))
+
+	breeds, err := client.ListBreeds(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, breeds, 1)
+	assert.Equal(t, "abys", breeds[0].ID)
+	assert.Equal(t, "Egypt", breeds[0].Origin)
+}
+
+func TestCatAPIClient_SearchImages(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/images/search", r.URL.Path)
+		assert.Equal(t, "beng", r.URL.Query().Get("breed_ids"))
+		assert.Equal(t, "8", r.URL.Query().Get("limit"))
+		assert.Empty(t, r.URL.Query().Get("page"))
+		w.Write([]byte(`[{"id": "img1", "url": "https://example.com/cat1.jpg"}]`))
+	}))
+	defer server.Close()
+
+	client := catapi.New("test-api-key", catapi.WithBaseURL(server.URL+"/v1"))
+
+	images, err := client.SearchImages(context.Background(), catapi.ImageSearch{
+		BreedIDs: []string{"beng"},
+		Limit:    8,
+	})
+	assert.NoError(t, err)
+	assert.Len(t, images, 1)
+	assert.Equal(t, "img1", images[0].ID)
+	assert.Equal(t, "https://example.com/cat1.jpg", images[0].URL)
+}
+
+func TestCatAPIClient_CreateFavouriteAndVote(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+
+		var body map[string]interface{}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, "img1", body["image_id"])
+		assert.Equal(t, "user-1", body["sub_id"])
+
+		switch r.URL.Path {
+		case "/v1/favourites":
+			w.Write([]byte(`{"message": "SUCCESS", "id": 42}`))
+		case "/v1/votes":
+			assert.Equal(t, float64(-1), body["value"])
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"message": "SUCCESS", "id": 7}`))
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	client := catapi.New("test-api-key", catapi.WithBaseURL(server.URL+"/v1"))
+
+	favourite, err := client.CreateFavourite(context.Background(), "img1", "user-1")
+	assert.NoError(t, err)
+	assert.Equal(t, 42, favourite.ID)
+
+	vote, err := client.CreateVote(context.Background(), catapi.NewVote{ImageID: "img1", SubID: "user-1", Value: -1})
+	assert.NoError(t, err)
+	assert.Equal(t, 7, vote.ID)
+}
+
+func TestCatAPIClient_Errors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/favourites":
+			http.Error(w, "NOT_FOUND", http.StatusNotFound)
+		default:
+			w.Write([]byte(`not json`))
+		}
+	}))
+	defer server.Close()
+
+	client := catapi.New("test-api-key", catapi.WithBaseURL(server.URL+"/v1/"))
+
+	_, _, err := client.ListFavourites(context.Background(), catapi.FavouriteQuery{})
+	assert.Error(t, err)
+	assert.True(t, catapi.IsNotFound(err))
+
+	var apiErr *catapi.APIError
+	assert.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusNotFound, apiErr.StatusCode)
+	assert.Equal(t, "/v1/favourites", apiErr.Path)
+
+	_, err = client.ListBreeds(context.Background())
+	assert.Error(t, err)
+	assert.False(t, catapi.IsNotFound(err))
+}
+
+// newBlockingServer answers every request with body once release is
+// closed, counting the requests it received.
+func newBlockingServer(t *testing.T, body string) (*httptest.Server, *atomic.Int32, chan struct{}) {
+	var requests atomic.Int32
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		select {
+		case <-release:
+			w.Write([]byte(body))
+		case <-r.Context().Done():
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests, release
+}
+
+// waitFor polls cond until it holds or the test times out.
+func waitFor(t *testing.T, cond func() bool) {
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met in time")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestCatAPIClient_CoalescesIdenticalRequests(t *testing.T) {
+	server, requests, release := newBlockingServer(t, `[{"id": "abys", "name": "Abyssinian"}]`)
+	client := catapi.New("test-api-key", catapi.WithBaseURL(server.URL))
+
+	const callers = 50
+	var wg sync.WaitGroup
+	results := make(chan []catapi.Breed, callers)
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			breeds, err := client.ListBreeds(context.Background())
+			assert.NoError(t, err)
+			results <- breeds
+		}()
+	}
+
+	waitFor(t, func() bool { return client.Stats().Coalesced == callers-1 })
+	close(release)
+	wg.Wait()
+	close(results)
+
+	assert.Equal(t, int32(1), requests.Load())
+	for breeds := range results {
+		assert.Len(t, breeds, 1)
+		assert.Equal(t, "abys", breeds[0].ID)
+	}
+
+	// Different queries are not merged
+	_, err := client.SearchImages(context.Background(), catapi.ImageSearch{BreedIDs: []string{"abys"}})
+	assert.NoError(t, err)
+	_, err = client.SearchImages(context.Background(), catapi.ImageSearch{BreedIDs: []string{"beng"}})
+	assert.NoError(t, err)
+	assert.Equal(t, int32(3), requests.Load())
+}
+
+func TestCatAPIClient_CoalescedRequestSurvivesOneCaller(t *testing.T) {
+	server, requests, release := newBlockingServer(t, `[]`)
+	client := catapi.New("test-api-key", catapi.WithBaseURL(server.URL))
+
+	firstCtx, cancelFirst := context.WithCancel(context.Background())
+	firstErr := make(chan error, 1)
+	go func() {
+		_, _, err := client.ListFavourites(firstCtx, catapi.FavouriteQuery{SubID: "user-1"})
+		firstErr <- err
+	}()
+	waitFor(t, func() bool { return requests.Load() == 1 })
+
+	secondErr := make(chan error, 1)
+	go func() {
+		_, _, err := client.ListFavourites(context.Background(), catapi.FavouriteQuery{SubID: "user-1"})
+		secondErr <- err
+	}()
+	waitFor(t, func() bool { return client.Stats().Coalesced == 1 })
+
+	// The first caller leaving does not cancel the request the second shares
+	cancelFirst()
+	assert.ErrorIs(t, <-firstErr, context.Canceled)
+	close(release)
+	assert.NoError(t, <-secondErr)
+	assert.Equal(t, int32(1), requests.Load())
+}
+
+func TestCatAPIClient_DoesNotCoalesceWrites(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Write([]byte(`{"message": "SUCCESS", "id": 1}`))
+	}))
+	defer server.Close()
+	client := catapi.New("test-api-key", catapi.WithBaseURL(server.URL))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := client.CreateVote(context.Background(), catapi.NewVote{ImageID: "img1", SubID: "user-1", Value: 1})
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(10), requests.Load())
+	assert.Zero(t, client.Stats().Coalesced)
+}
@@ -0,0 +1,235 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/beego/beego/v2/server/web/session"
+	"github.com/stretchr/testify/assert"
+
+	"myproject/catapi"
+	"myproject/controllers"
+	"myproject/elo"
+	"myproject/models"
+)
+
+func TestElo(t *testing.T) {
+	assert.Equal(t, 0.5, elo.Expected(1500, 1500))
+	// 400 points apart is ten to one
+	assert.InDelta(t, 10.0/11, elo.Expected(1900, 1500), 1e-9)
+	assert.InDelta(t, 1.0, elo.Expected(1900, 1500)+elo.Expected(1500, 1900), 1e-9)
+
+	winner, loser := elo.Update(1500, 1500, 32)
+	assert.Equal(t, 1516.0, winner)
+	assert.Equal(t, 1484.0, loser)
+
+	// An upset moves ratings further than an expected result
+	favourite, underdog := elo.Update(1700, 1500, 32)
+	upsetWinner, upsetLoser := elo.Update(1500, 1700, 32)
+	assert.Less(t, favourite-1700, upsetWinner-1500)
+	assert.InDelta(t, 32.0, (favourite-1700)+(upsetWinner-1500), 1e-9)
+
+	// Points are only ever passed between the two images
+	for _, r := range [][2]float64{{1500, 1500}, {1700, 1500}, {1200, 2100}} {
+		w, l := elo.Update(r[0], r[1], 24)
+		assert.InDelta(t, r[0]+r[1], w+l, 1e-9)
+		assert.Greater(t, w, r[0])
+		assert.Less(t, l, r[1])
+	}
+	assert.InDelta(t, 3200.0, underdog+favourite, 1e-9)
+	assert.InDelta(t, 3200.0, upsetWinner+upsetLoser, 1e-9)
+}
+
+func TestRatingStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ratings.json")
+	store, err := models.NewRatingStore(path, 32)
+	assert.NoError(t, err)
+
+	unknown := store.Get("a")
+	assert.Equal(t, elo.DefaultRating, unknown.Rating)
+	assert.Zero(t, unknown.Wins+unknown.Losses)
+
+	winner, loser, err := store.RecordDuel("a", "b")
+	assert.NoError(t, err)
+	assert.Equal(t, 1516.0, winner.Rating)
+	assert.Equal(t, 1484.0, loser.Rating)
+	assert.Equal(t, 1, winner.Wins)
+	assert.Equal(t, 1, loser.Losses)
+
+	_, _, err = store.RecordDuel("a", "c")
+	assert.NoError(t, err)
+	_, _, err = store.RecordDuel("c", "b")
+	assert.NoError(t, err)
+
+	_, _, err = store.RecordDuel("a", "a")
+	assert.Error(t, err)
+	assert.Equal(t, 2, store.Get("a").Wins)
+
+	ids := func(ratings []models.Rating) []string {
+		out := []string{}
+		for _, r := range ratings {
+			out = append(out, r.ImageID)
+		}
+		return out
+	}
+	assert.Equal(t, []string{"a", "c", "b"}, ids(store.Top(0)))
+	assert.Equal(t, []string{"a"}, ids(store.Top(1)))
+
+	// Ratings survive a restart
+	reopened, err := models.NewRatingStore(path, 32)
+	assert.NoError(t, err)
+	assert.Equal(t, store.Top(0), reopened.Top(0))
+}
+
+type duelResponse struct {
+	Images []controllers.DuelImage `json:"images"`
+	Winner models.Rating           `json:"winner"`
+	Loser  models.Rating           `json:"loser"`
+	Error  string                  `json:"error"`
+}
+
+// callDuel runs one DuelController action within sess, if any
+func callDuel(t *testing.T, client *catapi.Client, ratings *models.RatingStore, sess session.Store,
+	method, path string, action func(*controllers.DuelController)) (int, []byte) {
+	ctx, w := createTestContext(method, path)
+	if sess != nil {
+		ctx.Input.CruSession = sess
+	}
+	controller := &controllers.DuelController{APIKey: "test-api-key", API: client, Ratings: ratings}
+	controller.Init(ctx, "", "", controller)
+
+	action(controller)
+	return w.Code, w.Body.Bytes()
+}
+
+func duel(t *testing.T, client *catapi.Client, ratings *models.RatingStore, sess session.Store,
+	method, path string, action func(*controllers.DuelController)) (int, duelResponse) {
+	status, body := callDuel(t, client, ratings, sess, method, path, action)
+	var response duelResponse
+	assert.NoError(t, json.Unmarshal(body, &response))
+	return status, response
+}
+
+func TestDuelController(t *testing.T) {
+	_, client := newFakeCatAPI(t)
+	ratings, _ := models.NewRatingStore("", elo.DefaultK)
+	get := (*controllers.DuelController).Get
+	post := (*controllers.DuelController).Post
+
+	ctx, _ := createTestContext("GET", "/voting/duel")
+	sess := withSession(ctx, "user-1")
+
+	status, response := duel(t, client, ratings, sess, "GET", "/voting/duel", get)
+	assert.Equal(t, http.StatusOK, status)
+	if !assert.Len(t, response.Images, 2) {
+		return
+	}
+	a, b := response.Images[0], response.Images[1]
+	assert.NotEqual(t, a.ID, b.ID)
+	assert.NotEmpty(t, a.URL)
+	assert.Equal(t, elo.DefaultRating, a.Rating)
+
+	// Only the pair just served may be voted on
+	status, response = duel(t, client, ratings, sess, "POST", "/voting/duel?winner_id="+a.ID+"&loser_id=other", post)
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, "Pick one of the two images of your current duel", response.Error)
+	status, _ = duel(t, client, ratings, sess, "POST", "/voting/duel?winner_id="+a.ID+"&loser_id="+a.ID, post)
+	assert.Equal(t, http.StatusBadRequest, status)
+
+	status, response = duel(t, client, ratings, sess, "POST", "/voting/duel?winner_id="+b.ID+"&loser_id="+a.ID, post)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, b.ID, response.Winner.ImageID)
+	assert.Equal(t, 1516.0, response.Winner.Rating)
+	assert.Equal(t, 1484.0, response.Loser.Rating)
+
+	// Each duel counts once
+	status, _ = duel(t, client, ratings, sess, "POST", "/voting/duel?winner_id="+b.ID+"&loser_id="+a.ID, post)
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, 1, ratings.Get(b.ID).Wins)
+
+	// Ratings are served with the next duel's images
+	status, response = duel(t, client, ratings, sess, "GET", "/voting/duel", get)
+	assert.Equal(t, http.StatusOK, status)
+	for _, img := range response.Images {
+		assert.Equal(t, ratings.Get(img.ID).Rating, img.Rating)
+	}
+
+	ctx, _ = createTestContext("POST", "/voting/duel")
+	status, response = duel(t, client, ratings, withSession(ctx, ""), "POST", "/voting/duel?winner_id="+a.ID+"&loser_id="+b.ID, post)
+	assert.Equal(t, http.StatusUnauthorized, status)
+	assert.Equal(t, "Login required", response.Error)
+}
+
+func TestDuelController_Ranking(t *testing.T) {
+	_, client := newFakeCatAPI(t)
+	ratings, _ := models.NewRatingStore("", elo.DefaultK)
+	for _, d := range [][2]string{{"abys-1", "beng-2"}, {"abys-1", "gone"}, {"gone", "beng-2"}} {
+		_, _, err := ratings.RecordDuel(d[0], d[1])
+		assert.NoError(t, err)
+	}
+	ranking := (*controllers.DuelController).Ranking
+
+	status, body := callDuel(t, client, ratings, nil, "GET", "/voting/duel/ranking", ranking)
+	assert.Equal(t, http.StatusOK, status)
+	var entries []controllers.DuelRank
+	assert.NoError(t, json.Unmarshal(body, &entries))
+	if assert.Len(t, entries, 3) {
+		assert.Equal(t, []string{"abys-1", "gone", "beng-2"}, []string{entries[0].ImageID, entries[1].ImageID, entries[2].ImageID})
+		assert.Equal(t, []int{1, 2, 3}, []int{entries[0].Rank, entries[1].Rank, entries[2].Rank})
+		assert.Contains(t, entries[0].ImageURL, "abys-1")
+		assert.Equal(t, 2, entries[0].Wins)
+		assert.Greater(t, entries[0].Rating.Rating, elo.DefaultRating)
+		// Images the upstream doesn't know are listed without a URL
+		assert.Empty(t, entries[1].ImageURL)
+	}
+
+	status, body = callDuel(t, client, ratings, nil, "GET", "/voting/duel/ranking?limit=1", ranking)
+	assert.Equal(t, http.StatusOK, status)
+	assert.NoError(t, json.Unmarshal(body, &entries))
+	assert.Len(t, entries, 1)
+
+	status, response := duel(t, client, ratings, nil, "GET", "/voting/duel/ranking?limit=101", ranking)
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, "limit must be between 1 and 100", response.Error)
+}
+
+// Concurrent picks for the same duel count it once
+func TestDuelController_ConcurrentPosts(t *testing.T) {
+	_, client := newFakeCatAPI(t)
+	// Saving to a file widens the window between check and record
+	ratings, _ := models.NewRatingStore(filepath.Join(t.TempDir(), "ratings.json"), elo.DefaultK)
+	ctx, _ := createTestContext("GET", "/voting/duel")
+	sess := withSession(ctx, "user-1")
+
+	_, response := duel(t, client, ratings, sess, "GET", "/voting/duel", (*controllers.DuelController).Get)
+	if !assert.Len(t, response.Images, 2) {
+		return
+	}
+	a, b := response.Images[0], response.Images[1]
+
+	// Run the posts in parallel even on a single CPU
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(8))
+	const posts = 20
+	var wg sync.WaitGroup
+	var recorded atomic.Int32
+	for range posts {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			status, _ := callDuel(t, client, ratings, sess, "POST", "/voting/duel?winner_id="+a.ID+"&loser_id="+b.ID, (*controllers.DuelController).Post)
+			if status == http.StatusOK {
+				recorded.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(1), recorded.Load())
+	assert.Equal(t, 1, ratings.Get(a.ID).Wins)
+	assert.Equal(t, 1, ratings.Get(b.ID).Losses)
+}
@@ -0,0 +1,101 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	beecontext "github.com/beego/beego/v2/server/web/context"
+	"github.com/stretchr/testify/assert"
+
+	"myproject/catapi"
+	"myproject/controllers"
+	"myproject/fakecatapi"
+)
+
+// newFakeCatAPI starts the offline fake and returns a client pointed at it.
+func newFakeCatAPI(t *testing.T) (*httptest.Server, *catapi.Client) {
+	server := httptest.NewServer(fakecatapi.New(fakecatapi.WithSeed(1)))
+	t.Cleanup(server.Close)
+	return server, catapi.New("test-api-key", catapi.WithBaseURL(server.URL+"/v1"))
+}
+
+func TestFakeCatAPI_BreedsAndImages(t *testing.T) {
+	server, client := newFakeCatAPI(t)
+
+	breeds, err := client.ListBreeds(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, breeds, 10)
+	assert.Equal(t, "abys", breeds[0].ID)
+
+	images, err := client.SearchImages(context.Background(), catapi.ImageSearch{BreedIDs: []string{"beng"}, Limit: 5})
+	assert.NoError(t, err)
+	assert.Len(t, images, 5)
+
+	// Images are served by the fake itself so the UI works offline.
+	resp, err := http.Get(images[0].URL)
+	assert.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	// Ordered searches page through the catalogue and report the total.
+	resp, err = http.Get(server.URL + "/v1/images/search?breed_ids=beng&order=ASC&limit=5&page=2")
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, "12", resp.Header.Get("Pagination-Count"))
+
+	var lastPage []catapi.Image
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&lastPage))
+	assert.Len(t, lastPage, 2)
+}
+
+func TestFakeCatAPI_FavouritesAndVotes(t *testing.T) {
+	server, client := newFakeCatAPI(t)
+
+	created, err := client.CreateFavourite(context.Background(), "abys-1", "user-1")
+	assert.NoError(t, err)
+	assert.NotZero(t, created.ID)
+
+	_, err = client.CreateFavourite(context.Background(), "abys-1", "user-1")
+	var apiErr *catapi.APIError
+	assert.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusBadRequest, apiErr.StatusCode)
+
+	favourites, _, err := client.ListFavourites(context.Background(), catapi.FavouriteQuery{SubID: "user-1"})
+	assert.NoError(t, err)
+	assert.Len(t, favourites, 1)
+	assert.Equal(t, "user-1", favourites[0].SubID)
+	assert.Contains(t, favourites[0].Image.URL, "/images/abys-1.")
+
+	vote, err := client.CreateVote(context.Background(), catapi.NewVote{ImageID: "abys-1", SubID: "user-1", Value: 1})
+	assert.NoError(t, err)
+	assert.NotZero(t, vote.ID)
+
+	// Account resources require an API key, like the upstream.
+	resp, err := http.Get(server.URL + "/v1/votes")
+	assert.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+}
+
+func TestBreedSearchController_GetWithFakeCatAPI(t *testing.T) {
+	_, client := newFakeCatAPI(t)
+
+	controller := &controllers.BreedSearchController{APIKey: "test-api-key", API: client}
+
+	r, _ := http.NewRequest("GET", "/breed-search", nil)
+	w := httptest.NewRecorder()
+	ctx := beecontext.NewContext()
+	ctx.Reset(w, r)
+	controller.Init(ctx, "", "", controller)
+
+	controller.Get()
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "MISS", w.Header().Get("X-Cache"))
+	var breeds []controllers.CatBreed
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &breeds))
+	assert.Len(t, breeds, 10)
+}
@@ -1,23 +1,26 @@
 package tests
 
 import (
+	stdcontext "context"
 	"encoding/json"
 	"github.com/jarcoal/httpmock"
 	"github.com/stretchr/testify/assert"
+	"myproject/catapi"
 	"myproject/controllers"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
 	"testing"
-
-	beego "github.com/beego/beego/v2/server/web"
+	"time"
 )
 
 func TestFavoritesController_Get(t *testing.T) {
 	httpmock.Activate()
 	defer httpmock.DeactivateAndReset()
 
-	// Set up the mock response for the external API
-	httpmock.RegisterResponder("GET", "https://api.thecatapi.com/v1/favourites",
+	// Set up the mock response for the external API, filtered to the caller
+	httpmock.RegisterResponder("GET", "https://api.thecatapi.com/v1/favourites?sub_id=user-123",
 		httpmock.NewStringResponder(200, `[
 			{
 				"id": 232505403,
@@ -31,26 +34,23 @@ func TestFavoritesController_Get(t *testing.T) {
 			}
 		]`))
 
+	// Simulate a GET request from a logged-in user
+	ctx, rr := createTestContext("GET", "/favourites")
+	withSession(ctx, "user-123")
+
 	// Initialize the controller with the mock API key
 	controller := &controllers.FavoritesController{}
 	controller.APIKey = "test-api-key" // Directly inject API key
+	controller.Init(ctx, "", "", controller)
 
-	// Register the route
-	beego.Router("/favourites", controller)
-
-	// Simulate a GET request
-	req, err := http.NewRequest("GET", "/favourites", nil)
-	assert.NoError(t, err)
-
-	rr := httptest.NewRecorder()
-	beego.BeeApp.Handlers.ServeHTTP(rr, req)
+	controller.Get()
 
 	// Assert the status code is 200
 	assert.Equal(t, http.StatusOK, rr.Code, "Expected status 200, got %d", rr.Code)
 
 	// Decode the response body
 	var favorites []controllers.FavoriteResponse
-	err = json.Unmarshal(rr.Body.Bytes(), &favorites)
+	err := json.Unmarshal(rr.Body.Bytes(), &favorites)
 	assert.NoError(t, err, "Error decoding JSON response")
 	assert.NotEmpty(t, favorites)
 
@@ -60,3 +60,179 @@ func TestFavoritesController_Get(t *testing.T) {
 	assert.Equal(t, "user-123", favorites[0].SubID)
 	assert.Equal(t, "https://cdn2.thecatapi.com/images/MjAyMjUwMw.jpg", favorites[0].Image.URL)
 }
+
+func TestFavoritesController_GetRequiresLogin(t *testing.T) {
+	ctx, rr := createTestContext("GET", "/favourites")
+	withSession(ctx, "")
+
+	controller := &controllers.FavoritesController{APIKey: "test-api-key"}
+	controller.Init(ctx, "", "", controller)
+
+	controller.Get()
+
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
+}
+
+func TestFavoritesController_GetUpstreamError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "boom", http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	ctx, rr := createTestContext("GET", "/favourites")
+	withSession(ctx, "user-123")
+	controller := &controllers.FavoritesController{
+		APIKey: "test-api-key",
+		API: catapi.New("test-api-key",
+			catapi.WithBaseURL(server.URL),
+			catapi.WithRetry(catapi.RetryPolicy{MaxAttempts: 1}),
+		),
+	}
+	controller.Init(ctx, "", "", controller)
+
+	controller.Get()
+
+	assert.Equal(t, http.StatusBadGateway, rr.Code)
+	var body map[string]string
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &body))
+	assert.Equal(t, "Failed to fetch favorites", body["error"])
+}
+
+func TestFavoritesController_Delete(t *testing.T) {
+	_, client := newFakeCatAPI(t)
+
+	own, err := client.CreateFavourite(stdcontext.Background(), "abys-1", "user-1")
+	assert.NoError(t, err)
+	other, err := client.CreateFavourite(stdcontext.Background(), "abys-2", "user-2")
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name         string
+		id           string
+		expectedCode int
+	}{
+		{"own favorite", strconv.Itoa(own.ID), http.StatusOK},
+		{"already deleted", strconv.Itoa(own.ID), http.StatusNotFound},
+		{"someone else's favorite", strconv.Itoa(other.ID), http.StatusForbidden},
+		{"invalid id", "abc", http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, rr := createTestContext("DELETE", "/favourites/"+tt.id)
+			ctx.Input.SetParam(":id", tt.id)
+			withSession(ctx, "user-1")
+
+			controller := &controllers.FavoritesController{APIKey: "test-api-key", API: client}
+			controller.Init(ctx, "", "", controller)
+
+			controller.Delete()
+
+			assert.Equal(t, tt.expectedCode, rr.Code, rr.Body.String())
+		})
+	}
+
+	remaining, _, err := client.ListFavourites(stdcontext.Background(), catapi.FavouriteQuery{})
+	assert.NoError(t, err)
+	assert.Len(t, remaining, 1)
+	assert.Equal(t, other.ID, remaining[0].ID)
+}
+
+func TestFavoritesController_DeleteUpstreamError(t *testing.T) {
+	// Everything fails, except looking up the favorite while lookups is set
+	var lookups atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "GET" && lookups.Load() {
+			w.Write([]byte(`{"id": 7, "image_id": "abys-1", "sub_id": "user-1"}`))
+			return
+		}
+		http.Error(w, "boom", http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	deleteFavorite := func(client *catapi.Client) (int, string) {
+		ctx, rr := createTestContext("DELETE", "/favourites/7")
+		ctx.Input.SetParam(":id", "7")
+		withSession(ctx, "user-1")
+		controller := &controllers.FavoritesController{APIKey: "test-api-key", API: client}
+		controller.Init(ctx, "", "", controller)
+		controller.Delete()
+
+		var body map[string]string
+		assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &body))
+		return rr.Code, body["error"]
+	}
+	newClient := func() *catapi.Client {
+		return catapi.New("test-api-key",
+			catapi.WithBaseURL(server.URL),
+			catapi.WithRetry(catapi.RetryPolicy{MaxAttempts: 1}),
+			catapi.WithCircuitBreaker(1, time.Minute),
+		)
+	}
+
+	code, msg := deleteFavorite(newClient())
+	assert.Equal(t, http.StatusBadGateway, code)
+	assert.Equal(t, "Failed to fetch favorite", msg)
+
+	lookups.Store(true)
+	client := newClient()
+	code, msg = deleteFavorite(client)
+	assert.Equal(t, http.StatusBadGateway, code)
+	assert.Equal(t, "Failed to delete favorite", msg)
+
+	// The failed delete opened the circuit breaker
+	code, msg = deleteFavorite(client)
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, "Failed to fetch favorite", msg)
+}
+
+func TestFavoritesController_GetPaged(t *testing.T) {
+	_, client := newFakeCatAPI(t)
+
+	for i := 1; i <= 5; i++ {
+		_, err := client.CreateFavourite(stdcontext.Background(), "beng-"+strconv.Itoa(i), "user-1")
+		assert.NoError(t, err)
+	}
+	_, err := client.CreateFavourite(stdcontext.Background(), "beng-1", "user-2")
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name          string
+		query         string
+		expectedCode  int
+		expectedCount string
+		expectedIDs   []string
+	}{
+		{"second page newest first", "page=1&limit=2&order=desc", http.StatusOK, "5", []string{"beng-3", "beng-2"}},
+		{"own sub_id", "sub_id=user-1&limit=10", http.StatusOK, "5", []string{"beng-1", "beng-2", "beng-3", "beng-4", "beng-5"}},
+		{"another user's sub_id", "sub_id=user-2", http.StatusForbidden, "", nil},
+		{"limit too large", "limit=500", http.StatusBadRequest, "", nil},
+		{"bad order", "order=sideways", http.StatusBadRequest, "", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, rr := createTestContext("GET", "/favourites?"+tt.query)
+			withSession(ctx, "user-1")
+
+			controller := &controllers.FavoritesController{APIKey: "test-api-key", API: client}
+			controller.Init(ctx, "", "", controller)
+
+			controller.Get()
+
+			assert.Equal(t, tt.expectedCode, rr.Code, rr.Body.String())
+			if tt.expectedCode != http.StatusOK {
+				return
+			}
+			assert.Equal(t, tt.expectedCount, rr.Header().Get("Pagination-Count"))
+
+			var favorites []controllers.FavoriteResponse
+			assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &favorites))
+			var imageIDs []string
+			for _, favorite := range favorites {
+				imageIDs = append(imageIDs, favorite.ImageID)
+			}
+			assert.Equal(t, tt.expectedIDs, imageIDs)
+		})
+	}
+}
@@ -0,0 +1,317 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"myproject/catapi"
+	"myproject/controllers"
+	"myproject/i18n"
+)
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		name           string
+		preferred      string
+		acceptLanguage string
+		want           string
+	}{
+		{"nothing asked", "", "", "en"},
+		{"preferred wins", "bn", "de-DE,de;q=0.9", "bn"},
+		{"unsupported preference falls through", "fr", "de", "de"},
+		{"region and case are ignored", "", "DE-at", "de"},
+		{"highest q wins", "", "en;q=0.5, bn;q=0.8, de;q=0.1", "bn"},
+		{"unsupported languages are skipped", "", "fr-FR, ja;q=0.9, de;q=0.3", "de"},
+		{"q=0 refuses a language", "", "de;q=0, fr", "en"},
+		{"malformed q is skipped", "", "bn;q=high, de;q=0.2", "de"},
+		{"wildcard", "", "*", "en"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, i18n.Negotiate(tt.preferred, tt.acceptLanguage))
+		})
+	}
+}
+
+func TestT(t *testing.T) {
+	assert.Equal(t, "Abstimmen", i18n.T("de", "nav.voting"))
+	assert.Equal(t, "ভোট", i18n.T("bn", "nav.voting"))
+	assert.Equal(t, "Voting", i18n.T("fr", "nav.voting"))
+	assert.Equal(t, "no.such.key", i18n.T("de", "no.such.key"))
+}
+
+func TestError(t *testing.T) {
+	tests := []struct {
+		lang, msg, want string
+	}{
+		{"en", "Login required", "Login required"},
+		{"de", "Login required", "Anmeldung erforderlich"},
+		{"de", "limit must be between 1 and 25", "limit muss zwischen 1 und 25 liegen"},
+		{"de", "min_energy_level must be between 1 and 5", "min_energy_level muss zwischen 1 und 5 liegen"},
+		{"de", "Breed not found: xyz", "Rasse nicht gefunden: xyz"},
+		{"bn", "ids must list 2 to 4 different breeds", "ids এ 2 থেকে 4টি ভিন্ন জাত থাকতে হবে"},
+		{"bn", "sort must be name, origin or life_span", "sort অবশ্যই name, origin অথবা life_span হতে হবে"},
+		{"de", "something nobody translated", "something nobody translated"},
+		{"fr", "Login required", "Login required"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.lang+" "+tt.msg, func(t *testing.T) {
+			assert.Equal(t, tt.want, i18n.Error(tt.lang, tt.msg))
+		})
+	}
+}
+
+// Every catalogue must cover the English UI strings and keep the verbs of
+// each error message
+func TestCataloguesComplete(t *testing.T) {
+	type catalogue struct {
+		UI     map[string]string `json:"ui"`
+		Errors map[string]string `json:"errors"`
+	}
+	read := func(lang string) catalogue {
+		data, err := os.ReadFile(filepath.Join("..", "i18n", "locales", lang+".json"))
+		assert.NoError(t, err)
+		var c catalogue
+		assert.NoError(t, json.Unmarshal(data, &c))
+		return c
+	}
+	verbs := regexp.MustCompile(`%[sd]`)
+
+	en := read("en")
+	de := read("de")
+	for _, lang := range i18n.Supported[1:] {
+		c := read(lang)
+		for key := range en.UI {
+			assert.NotEmpty(t, c.UI[key], "%s is missing ui %q", lang, key)
+		}
+		for key, text := range c.UI {
+			assert.Equal(t, verbs.FindAllString(en.UI[key], -1), verbs.FindAllString(text, -1), "%s %q", lang, key)
+		}
+		for msg := range de.Errors {
+			assert.Contains(t, c.Errors, msg, "%s is missing error %q", lang, msg)
+		}
+		for msg, translation := range c.Errors {
+			assert.Equal(t, verbs.FindAllString(msg, -1), verbs.FindAllString(translation, -1), "%s %q", lang, msg)
+		}
+	}
+}
+
+// stubTranslator translates by tagging text with the language. While
+// release is set, translations wait for it to be closed.
+type stubTranslator struct {
+	calls   atomic.Int32
+	running atomic.Int32
+	err     error
+	release chan struct{}
+
+	mu   sync.Mutex
+	most int32
+}
+
+func (s *stubTranslator) Translate(ctx context.Context, text, lang string) (string, error) {
+	s.calls.Add(1)
+	s.mu.Lock()
+	s.most = max(s.most, s.running.Add(1))
+	s.mu.Unlock()
+	defer s.running.Add(-1)
+	if s.release != nil {
+		<-s.release
+	}
+	if s.err != nil {
+		return "", s.err
+	}
+	return "[" + lang + "] " + text, nil
+}
+
+func TestDescriptions(t *testing.T) {
+	abys := catapi.Breed{ID: "abys", Description: "Easy to care for."}
+	beng := catapi.Breed{ID: "beng", Description: "A lot of fun."}
+	overrides := i18n.Overrides{"de": {"abys": "Pflegeleicht."}}
+	ctx := context.Background()
+
+	source := &stubTranslator{}
+	descriptions := i18n.NewDescriptions(overrides, source)
+	assert.Equal(t, "Easy to care for.", descriptions.Describe(ctx, "en", abys))
+	assert.Equal(t, "Pflegeleicht.", descriptions.Describe(ctx, "de", abys))
+	// English is served until the translation is ready
+	assert.Equal(t, "A lot of fun.", descriptions.Describe(ctx, "de", beng))
+	descriptions.Wait()
+	assert.Equal(t, "[de] A lot of fun.", descriptions.Describe(ctx, "de", beng))
+	assert.Equal(t, "[de] A lot of fun.", descriptions.Describe(ctx, "de", beng))
+	assert.Equal(t, int32(1), source.calls.Load())
+
+	// A changed description is translated again
+	beng.Description = "Very active."
+	descriptions.Describe(ctx, "de", beng)
+	descriptions.Wait()
+	assert.Equal(t, "[de] Very active.", descriptions.Describe(ctx, "de", beng))
+	assert.Equal(t, int32(2), source.calls.Load())
+
+	// Failures fall back to English and are not retried for a while
+	source.err = errors.New("translator down")
+	assert.Equal(t, "Easy to care for.", descriptions.Describe(ctx, "bn", abys))
+	descriptions.Wait()
+	source.err = nil
+	assert.Equal(t, "Easy to care for.", descriptions.Describe(ctx, "bn", abys))
+	descriptions.Wait()
+	assert.Equal(t, int32(3), source.calls.Load())
+
+	// Without a source only overrides are translated
+	descriptions = i18n.NewDescriptions(overrides, nil)
+	assert.Equal(t, "A lot of fun.", descriptions.Describe(ctx, "de", catapi.Breed{ID: "beng", Description: "A lot of fun."}))
+}
+
+func TestDescriptions_RetriesFailures(t *testing.T) {
+	abys := catapi.Breed{ID: "abys", Description: "Easy to care for."}
+	source := &stubTranslator{err: errors.New("translator down")}
+	descriptions := i18n.NewDescriptions(nil, source, i18n.WithRetryAfter(10*time.Millisecond))
+
+	descriptions.Describe(context.Background(), "bn", abys)
+	descriptions.Wait()
+	source.err = nil
+	time.Sleep(20 * time.Millisecond)
+	descriptions.Describe(context.Background(), "bn", abys)
+	descriptions.Wait()
+	assert.Equal(t, "[bn] Easy to care for.", descriptions.Describe(context.Background(), "bn", abys))
+}
+
+// A slow translator neither holds up requests nor gets flooded
+func TestDescriptions_TranslatesInBackground(t *testing.T) {
+	source := &stubTranslator{release: make(chan struct{})}
+	descriptions := i18n.NewDescriptions(nil, source)
+
+	start := time.Now()
+	for i := range 60 {
+		breed := catapi.Breed{ID: fmt.Sprintf("b%d", i), Description: fmt.Sprintf("Breed %d.", i)}
+		assert.Equal(t, breed.Description, descriptions.Describe(context.Background(), "de", breed))
+		// Asking again while it is being translated starts nothing new
+		descriptions.Describe(context.Background(), "de", breed)
+	}
+	assert.Less(t, time.Since(start), time.Second)
+
+	waitFor(t, func() bool { return source.running.Load() == 4 })
+	close(source.release)
+	descriptions.Wait()
+	assert.Equal(t, int32(60), source.calls.Load())
+	assert.Equal(t, int32(4), source.most)
+	assert.Equal(t, "[de] Breed 7.", descriptions.Describe(context.Background(), "de", catapi.Breed{ID: "b7", Description: "Breed 7."}))
+}
+
+func TestLoadOverrides(t *testing.T) {
+	dir := t.TempDir()
+
+	overrides, err := i18n.LoadOverrides(filepath.Join(dir, "missing.json"))
+	assert.NoError(t, err)
+	assert.Empty(t, overrides)
+
+	path := filepath.Join(dir, "descriptions.json")
+	assert.NoError(t, os.WriteFile(path, []byte(`{"bn": {"abys": "আবিসিনিয়ান"}}`), 0644))
+	overrides, err = i18n.LoadOverrides(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "আবিসিনিয়ান", overrides["bn"]["abys"])
+
+	assert.NoError(t, os.WriteFile(path, []byte(`{"bn": "oops"}`), 0644))
+	_, err = i18n.LoadOverrides(path)
+	assert.Error(t, err)
+}
+
+func TestLibreTranslate(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]string
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(t, "/translate", r.URL.Path)
+		assert.Equal(t, "en", req["source"])
+		assert.Equal(t, "secret", req["api_key"])
+		if req["target"] != "de" {
+			http.Error(w, `{"error":"unsupported"}`, http.StatusBadRequest)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{"translatedText": strings.ToUpper(req["q"])})
+	}))
+	defer server.Close()
+
+	translator := &i18n.LibreTranslate{URL: server.URL + "/", APIKey: "secret"}
+	text, err := translator.Translate(context.Background(), "cat", "de")
+	assert.NoError(t, err)
+	assert.Equal(t, "CAT", text)
+
+	_, err = translator.Translate(context.Background(), "cat", "bn")
+	assert.Error(t, err)
+}
+
+func TestLocalizedErrors(t *testing.T) {
+	_, client := newFakeCatAPI(t)
+
+	ctx, w := createTestContext("POST", "/breed-search/match?kids=maybe")
+	ctx.Request.Header.Set("Accept-Language", "de-DE,de;q=0.9,en;q=0.5")
+	controller := &controllers.BreedSearchController{APIKey: "test-api-key", API: client}
+	controller.Init(ctx, "", "", controller)
+	controller.Match()
+
+	var response map[string]string
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, "kids muss true oder false sein", response["error"])
+	assert.Equal(t, "de", w.Header().Get("Content-Language"))
+
+	// The query parameter beats the header and is remembered
+	ctx, w = createTestContext("GET", "/voting?lang=bn")
+	ctx.Request.Header.Set("Accept-Language", "de")
+	voting := initController(ctx)
+	voting.Post()
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "লগ ইন করা প্রয়োজন", response["error"])
+	assert.Contains(t, w.Header().Get("Set-Cookie"), "lang=bn")
+
+	ctx, w = createTestContext("GET", "/voting")
+	ctx.Request.Header.Set("Accept-Language", "de")
+	ctx.Request.AddCookie(&http.Cookie{Name: "lang", Value: "bn"})
+	voting = initController(ctx)
+	voting.Post()
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "লগ ইন করা প্রয়োজন", response["error"])
+}
+
+func TestLocalizedDescriptions(t *testing.T) {
+	_, client := newFakeCatAPI(t)
+	path := filepath.Join(t.TempDir(), "descriptions.json")
+	assert.NoError(t, os.WriteFile(path, []byte(`{"de": {"abys": "Die Abessinier ist pflegeleicht."}}`), 0644))
+	withConfig(t, "description_overrides", path)
+
+	get := func(query string) []controllers.CatBreed {
+		ctx, w := createTestContext("GET", "/breed-search?"+query)
+		controller := &controllers.BreedSearchController{APIKey: "test-api-key", API: client}
+		controller.Init(ctx, "", "", controller)
+		controller.Get()
+		var list []controllers.CatBreed
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+		return list
+	}
+
+	list := get("origin=Egypt&lang=de")
+	if assert.Len(t, list, 1) {
+		assert.Equal(t, "Die Abessinier ist pflegeleicht.", list[0].Description)
+	}
+	// Without an override or translate_url the description stays English
+	for _, b := range get("origin=Greece&lang=de") {
+		assert.True(t, strings.HasPrefix(b.Description, "Native to the Greek islands"), b.Description)
+	}
+	list = get("origin=Egypt")
+	if assert.Len(t, list, 1) {
+		assert.True(t, strings.HasPrefix(list[0].Description, "The Abyssinian"), list[0].Description)
+	}
+}
@@ -0,0 +1,161 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"myproject/catapi"
+	"myproject/controllers"
+)
+
+// newBatchImageServer answers images/search with limit images numbered on
+// from the last batch, recording the limits asked for. While failing is
+// set, searches with a limit fail.
+func newBatchImageServer(t *testing.T) (*httptest.Server, *[]string, *atomic.Bool) {
+	var mu sync.Mutex
+	var limits []string
+	var next int
+	failing := &atomic.Bool{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		limit := r.URL.Query().Get("limit")
+		if limit != "" && failing.Load() {
+			http.Error(w, "unavailable", http.StatusInternalServerError)
+			return
+		}
+		n, _ := strconv.Atoi(limit)
+		mu.Lock()
+		limits = append(limits, limit)
+		images := []catapi.Image{}
+		for range max(n, 1) {
+			next++
+			images = append(images, catapi.Image{ID: fmt.Sprintf("img-%d", next), URL: fmt.Sprintf("https://example.com/img-%d.jpg", next)})
+		}
+		mu.Unlock()
+		json.NewEncoder(w).Encode(images)
+	}))
+	t.Cleanup(server.Close)
+	return server, &limits, failing
+}
+
+func TestImagePool_RefillsInBatches(t *testing.T) {
+	server, limits, _ := newBatchImageServer(t)
+	client := catapi.New("test_api_key", catapi.WithBaseURL(server.URL))
+	pool := catapi.NewImagePool(client, 6, 4)
+	defer pool.Close()
+
+	// Filled up front, two batches of four trimmed to the size
+	waitFor(t, func() bool { return pool.Stats().Depth == 6 })
+	assert.Equal(t, []string{"4", "4"}, *limits)
+
+	seen := map[string]bool{}
+	for range 3 {
+		image, ok := pool.Take()
+		assert.True(t, ok)
+		assert.False(t, seen[image.ID], "image %s served twice", image.ID)
+		seen[image.ID] = true
+	}
+	assert.True(t, seen["img-1"], "images are served in the order fetched")
+
+	// Dropping to half full triggers another batch
+	waitFor(t, func() bool { return pool.Stats().Depth == 6 })
+	stats := pool.Stats()
+	assert.Equal(t, 6, stats.Capacity)
+	assert.Equal(t, int64(3), stats.Hits)
+	assert.Zero(t, stats.Misses)
+	assert.Equal(t, int64(3), stats.Refills)
+	assert.Zero(t, stats.RefillErrors)
+	assert.False(t, stats.RefilledAt.IsZero())
+	assert.GreaterOrEqual(t, stats.MaxRefillMs, stats.AvgRefillMs)
+}
+
+func TestImagePool_SkipsQueuedImages(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[{"id": "a", "url": "https://example.com/a.jpg"}, {"id": "a", "url": "https://example.com/a.jpg"}, {"id": "b", "url": "https://example.com/b.jpg"}]`))
+	}))
+	defer server.Close()
+	client := catapi.New("test_api_key", catapi.WithBaseURL(server.URL))
+	pool := catapi.NewImagePool(client, 5, 3)
+	defer pool.Close()
+
+	// The upstream has nothing new, so the pool stops short of full
+	waitFor(t, func() bool { return pool.Stats().Refills == 2 })
+	assert.Equal(t, 2, pool.Stats().Depth)
+}
+
+func TestImagePool_Disabled(t *testing.T) {
+	pool := catapi.NewImagePool(nil, 0, 0)
+	defer pool.Close()
+
+	_, ok := pool.Take()
+	assert.False(t, ok)
+	assert.Equal(t, catapi.PoolStats{Misses: 1}, pool.Stats())
+}
+
+func TestVotingController_ServesPooledImages(t *testing.T) {
+	server, limits, _ := newBatchImageServer(t)
+	client := catapi.New("test_api_key", catapi.WithBaseURL(server.URL))
+	pool := catapi.NewImagePool(client, 4, 4)
+	defer pool.Close()
+	waitFor(t, func() bool { return pool.Stats().Depth == 4 })
+
+	ctx, w := createTestContext("GET", "/voting")
+	controller := initController(ctx)
+	controller.API, controller.Pool = client, pool
+	controller.Get()
+
+	var body map[string]string
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, "img-1", body["image_id"])
+	assert.Equal(t, int64(1), pool.Stats().Hits)
+	// No request without a limit, i.e. no direct fetch
+	assert.NotContains(t, *limits, "")
+}
+
+func TestVotingController_FallsBackWhenPoolIsEmpty(t *testing.T) {
+	server, _, failing := newBatchImageServer(t)
+	failing.Store(true)
+	client := catapi.New("test_api_key", catapi.WithBaseURL(server.URL), catapi.WithRetry(catapi.RetryPolicy{MaxAttempts: 1}))
+	pool := catapi.NewImagePool(client, 4, 4)
+	defer pool.Close()
+	waitFor(t, func() bool { return pool.Stats().RefillErrors > 0 })
+
+	ctx, w := createTestContext("GET", "/voting")
+	controller := initController(ctx)
+	controller.API, controller.Pool = client, pool
+	controller.Get()
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var body map[string]string
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.NotEmpty(t, body["image_id"])
+
+	stats := pool.Stats()
+	assert.Zero(t, stats.Depth)
+	assert.Equal(t, int64(1), stats.Misses)
+	assert.Contains(t, stats.LastError, "500")
+}
+
+func TestStatsController_ReportsImagePool(t *testing.T) {
+	_, client := newFakeCatAPI(t)
+	ctx, w := createTestContext("GET", "/debug/stats")
+	controller := &controllers.StatsController{APIKey: "test-api-key", API: client}
+	controller.Init(ctx, "", "", controller)
+
+	controller.Get()
+
+	var body struct {
+		ImagePool *catapi.PoolStats `json:"image_pool"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	if assert.NotNil(t, body.ImagePool) {
+		assert.Equal(t, 20, body.ImagePool.Capacity)
+	}
+}
@@ -0,0 +1,218 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"myproject/catapi"
+	"myproject/controllers"
+	"myproject/models"
+)
+
+func TestVoteStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "votes.jsonl")
+	store, err := models.NewVoteStore(path)
+	assert.NoError(t, err)
+
+	now := time.Date(2024, 6, 10, 12, 0, 0, 0, time.UTC)
+	for i, v := range []models.Vote{
+		{ImageID: "a", Value: 1, CreatedAt: now.Add(-time.Hour)},
+		{ImageID: "a", Value: 1, CreatedAt: now.Add(-2 * time.Hour)},
+		{ImageID: "a", Value: -1, CreatedAt: now.Add(-3 * time.Hour)},
+		{ImageID: "b", Value: 1, CreatedAt: now.Add(-30 * time.Minute)},
+		{ImageID: "c", Value: 1, CreatedAt: now.Add(-10 * time.Minute)},
+		{ImageID: "c", Value: 1, CreatedAt: now.Add(-72 * time.Hour)},
+		{ImageID: "d", Value: -1, CreatedAt: now.Add(-time.Minute)},
+	} {
+		v.ID, v.SubID = i+1, "user-"+strconv.Itoa(i+1)
+		assert.NoError(t, store.Add(v))
+	}
+
+	scoreOf := func(scores []models.ImageScore) []string {
+		out := []string{}
+		for _, s := range scores {
+			out = append(out, s.ImageID+":"+strconv.Itoa(s.Score))
+		}
+		return out
+	}
+
+	// a and c tie on score and likes; c was voted on more recently
+	assert.Equal(t, []string{"c:2", "a:1", "b:1", "d:-1"}, scoreOf(store.Leaderboard(time.Time{}, 0)))
+	// Within a day a still has more likes than b and c
+	assert.Equal(t, []string{"a:1", "c:1", "b:1", "d:-1"}, scoreOf(store.Leaderboard(now.Add(-24*time.Hour), 0)))
+	assert.Equal(t, []string{"c:1", "b:1"}, scoreOf(store.Leaderboard(now.Add(-time.Hour), 2)))
+
+	top := store.Leaderboard(time.Time{}, 2)[1]
+	assert.Equal(t, models.ImageScore{ImageID: "a", Score: 1, Likes: 2, Dislikes: 1, LastVote: now.Add(-time.Hour)}, top)
+
+	assert.NoError(t, store.Remove(5))
+	assert.NoError(t, store.Remove(99))
+	assert.Equal(t, []string{"a:1", "b:1", "c:1", "d:-1"}, scoreOf(store.Leaderboard(time.Time{}, 0)))
+
+	// The log is replayed on start
+	reopened, err := models.NewVoteStore(path)
+	assert.NoError(t, err)
+	assert.Equal(t, store.Leaderboard(time.Time{}, 0), reopened.Leaderboard(time.Time{}, 0))
+
+	assert.NoError(t, os.WriteFile(path, []byte("{\"id\": 1}\nnot json\n"), 0600))
+	_, err = models.NewVoteStore(path)
+	assert.ErrorContains(t, err, "line 2")
+
+	missing, err := models.NewVoteStore(filepath.Join(t.TempDir(), "none.jsonl"))
+	assert.NoError(t, err)
+	assert.Empty(t, missing.Leaderboard(time.Time{}, 0))
+}
+
+// Voting again on an image replaces a user's say instead of adding to it
+func TestVoteStore_CountsLatestVotePerUser(t *testing.T) {
+	store, _ := models.NewVoteStore("")
+	now := time.Date(2024, 6, 10, 12, 0, 0, 0, time.UTC)
+	for i, v := range []models.Vote{
+		{ImageID: "a", SubID: "user-1", Value: 1, CreatedAt: now.Add(-time.Hour)},
+		{ImageID: "a", SubID: "user-1", Value: 1, CreatedAt: now.Add(-time.Hour)},
+		{ImageID: "a", SubID: "user-1", Value: 1, CreatedAt: now.Add(-time.Hour)},
+		{ImageID: "a", SubID: "user-1", Value: 1, CreatedAt: now.Add(-time.Hour)},
+		{ImageID: "b", SubID: "user-1", Value: 1, CreatedAt: now.Add(-3 * time.Hour)},
+		{ImageID: "b", SubID: "user-2", Value: 1, CreatedAt: now.Add(-3 * time.Hour)},
+		{ImageID: "c", SubID: "user-1", Value: 1, CreatedAt: now.Add(-48 * time.Hour)},
+		{ImageID: "c", SubID: "user-1", Value: -1, CreatedAt: now.Add(-2 * time.Hour)},
+	} {
+		v.ID = i + 1
+		assert.NoError(t, store.Add(v))
+	}
+
+	scores := store.Leaderboard(time.Time{}, 0)
+	if assert.Len(t, scores, 3) {
+		// Four likes from one user are worth less than one each from two
+		assert.Equal(t, models.ImageScore{ImageID: "b", Score: 2, Likes: 2, LastVote: now.Add(-3 * time.Hour)}, scores[0])
+		assert.Equal(t, models.ImageScore{ImageID: "a", Score: 1, Likes: 1, LastVote: now.Add(-time.Hour)}, scores[1])
+		// The later dislike replaces the like
+		assert.Equal(t, models.ImageScore{ImageID: "c", Score: -1, Dislikes: 1, LastVote: now.Add(-2 * time.Hour)}, scores[2])
+	}
+
+	// Undoing the latest vote brings back the one before
+	assert.NoError(t, store.Remove(8))
+	scores = store.Leaderboard(now.Add(-24*time.Hour), 0)
+	assert.Len(t, scores, 2)
+	assert.Equal(t, 1, store.Leaderboard(time.Time{}, 0)[2].Score)
+}
+
+type leaderboardResponse struct {
+	Window  string                         `json:"window"`
+	Since   *time.Time                     `json:"since"`
+	Entries []controllers.LeaderboardEntry `json:"entries"`
+	Error   string                         `json:"error"`
+}
+
+// getLeaderboard calls LeaderboardController.Get over votes
+func getLeaderboard(t *testing.T, client *catapi.Client, votes *models.VoteStore, query string) (int, leaderboardResponse) {
+	ctx, w := createTestContext("GET", "/leaderboard?"+query)
+	controller := &controllers.LeaderboardController{APIKey: "test-api-key", API: client, Votes: votes}
+	controller.Init(ctx, "", "", controller)
+
+	controller.Get()
+
+	var response leaderboardResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	return w.Code, response
+}
+
+func TestLeaderboardController_Get(t *testing.T) {
+	_, client := newFakeCatAPI(t)
+	votes, _ := models.NewVoteStore("")
+	now := time.Now().UTC()
+	for i, v := range []models.Vote{
+		{ImageID: "abys-1", Value: 1, CreatedAt: now.Add(-time.Hour)},
+		{ImageID: "abys-1", Value: 1, CreatedAt: now.Add(-2 * time.Hour)},
+		{ImageID: "beng-2", Value: 1, CreatedAt: now.Add(-3 * time.Hour)},
+		{ImageID: "beng-2", Value: 1, CreatedAt: now.Add(-4 * 24 * time.Hour)},
+		{ImageID: "siam-3", Value: 1, CreatedAt: now.Add(-30 * 24 * time.Hour)},
+		{ImageID: "siam-3", Value: 1, CreatedAt: now.Add(-31 * 24 * time.Hour)},
+		{ImageID: "siam-3", Value: 1, CreatedAt: now.Add(-32 * 24 * time.Hour)},
+		{ImageID: "gone", Value: 1, CreatedAt: now.Add(-time.Minute)},
+	} {
+		v.ID, v.SubID = i+1, "user-"+strconv.Itoa(i+1)
+		assert.NoError(t, votes.Add(v))
+	}
+
+	status, response := getLeaderboard(t, client, votes, "")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "week", response.Window)
+	if assert.NotNil(t, response.Since) {
+		assert.WithinDuration(t, now.Add(-7*24*time.Hour), *response.Since, time.Minute)
+	}
+	if assert.Len(t, response.Entries, 3) {
+		assert.Equal(t, "abys-1", response.Entries[0].ImageID)
+		assert.Contains(t, response.Entries[0].ImageURL, "abys-1")
+		// Equal scores share a rank
+		assert.Equal(t, []int{1, 1, 3}, []int{response.Entries[0].Rank, response.Entries[1].Rank, response.Entries[2].Rank})
+		assert.Equal(t, "beng-2", response.Entries[1].ImageID)
+		// Images the upstream doesn't know are listed without a URL
+		assert.Equal(t, "gone", response.Entries[2].ImageID)
+		assert.Empty(t, response.Entries[2].ImageURL)
+	}
+
+	status, response = getLeaderboard(t, client, votes, "window=day&limit=1")
+	assert.Equal(t, http.StatusOK, status)
+	if assert.Len(t, response.Entries, 1) {
+		assert.Equal(t, "abys-1", response.Entries[0].ImageID)
+		assert.Equal(t, 2, response.Entries[0].Score)
+	}
+
+	status, response = getLeaderboard(t, client, votes, "window=ALL")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Nil(t, response.Since)
+	if assert.Len(t, response.Entries, 4) {
+		assert.Equal(t, "siam-3", response.Entries[0].ImageID)
+		assert.Equal(t, 3, response.Entries[0].Likes)
+	}
+
+	status, response = getLeaderboard(t, client, votes, "window=month")
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, "window must be day, week or all", response.Error)
+
+	status, response = getLeaderboard(t, client, votes, "limit=0")
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, "limit must be between 1 and 100", response.Error)
+}
+
+// Votes, changes and undos through the app all reach the leaderboard
+func TestLeaderboard_FollowsVotes(t *testing.T) {
+	_, client := newFakeCatAPI(t)
+	votes, _ := models.NewVoteStore("")
+
+	ctx, w := createTestContext("POST", "/voting?action=dislike&image_id=abys-1")
+	withSession(ctx, "user-1")
+	voting := initController(ctx)
+	voting.API, voting.Votes = client, votes
+	voting.Post()
+	var posted struct {
+		VoteID int `json:"vote_id"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &posted))
+
+	scores := votes.Leaderboard(time.Time{}, 0)
+	if assert.Len(t, scores, 1) {
+		assert.Equal(t, models.ImageScore{ImageID: "abys-1", Score: -1, Dislikes: 1, LastVote: scores[0].LastVote}, scores[0])
+	}
+
+	w, response := changeVote(t, client, votes, "PUT", "user-1", strconv.Itoa(posted.VoteID), "value=like")
+	assert.Equal(t, http.StatusOK, w.Code)
+	scores = votes.Leaderboard(time.Time{}, 0)
+	if assert.Len(t, scores, 1) {
+		assert.Equal(t, 1, scores[0].Score)
+		assert.Equal(t, 0, scores[0].Dislikes)
+	}
+
+	newID := strconv.Itoa(int(response["id"].(float64)))
+	w, _ = changeVote(t, client, votes, "DELETE", "user-1", newID, "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, votes.Leaderboard(time.Time{}, 0))
+}
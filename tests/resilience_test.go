@@ -0,0 +1,161 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"myproject/catapi"
+)
+
+// newFailingServer fails the first failures requests with status and then
+// answers with a single breed, counting every request it received.
+func newFailingServer(t *testing.T, failures int32, status int, header http.Header) (*httptest.Server, *atomic.Int32) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) <= failures {
+			for k, v := range header {
+				w.Header()[k] = v
+			}
+			w.WriteHeader(status)
+			return
+		}
+		w.Write([]byte(`[{"id": "abys", "name": "Abyssinian"}]`))
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+var fastRetry = catapi.RetryPolicy{
+	MaxAttempts:   3,
+	BaseDelay:     time.Millisecond,
+	MaxDelay:      5 * time.Millisecond,
+	MaxRetryAfter: 2 * time.Second,
+}
+
+func TestCatAPIClient_RetriesTransientErrors(t *testing.T) {
+	server, requests := newFailingServer(t, 2, http.StatusServiceUnavailable, nil)
+	client := catapi.New("test-api-key", catapi.WithBaseURL(server.URL+"/v1"), catapi.WithRetry(fastRetry))
+
+	breeds, err := client.ListBreeds(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, breeds, 1)
+	assert.Equal(t, int32(3), requests.Load())
+	assert.Equal(t, int64(2), client.Stats().Retries)
+}
+
+func TestCatAPIClient_GivesUpAfterMaxAttempts(t *testing.T) {
+	server, requests := newFailingServer(t, 10, http.StatusBadGateway, nil)
+	client := catapi.New("test-api-key", catapi.WithBaseURL(server.URL+"/v1"), catapi.WithRetry(fastRetry))
+
+	_, err := client.ListBreeds(context.Background())
+	var apiErr *catapi.APIError
+	assert.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusBadGateway, apiErr.StatusCode)
+	assert.Equal(t, int32(3), requests.Load())
+}
+
+func TestCatAPIClient_DoesNotRetryClientErrors(t *testing.T) {
+	server, requests := newFailingServer(t, 10, http.StatusNotFound, nil)
+	client := catapi.New("test-api-key", catapi.WithBaseURL(server.URL+"/v1"), catapi.WithRetry(fastRetry))
+
+	_, err := client.ListBreeds(context.Background())
+	assert.True(t, catapi.IsNotFound(err))
+	assert.Equal(t, int32(1), requests.Load())
+}
+
+func TestCatAPIClient_DoesNotRetryWrites(t *testing.T) {
+	server, requests := newFailingServer(t, 10, http.StatusServiceUnavailable, nil)
+	client := catapi.New("test-api-key", catapi.WithBaseURL(server.URL+"/v1"), catapi.WithRetry(fastRetry))
+
+	_, err := client.CreateVote(context.Background(), catapi.NewVote{ImageID: "img", SubID: "user-1", Value: 1})
+	assert.Error(t, err)
+	assert.Equal(t, int32(1), requests.Load())
+}
+
+func TestCatAPIClient_HonoursRetryAfter(t *testing.T) {
+	header := http.Header{"Retry-After": []string{"1"}}
+	server, requests := newFailingServer(t, 1, http.StatusTooManyRequests, header)
+	client := catapi.New("test-api-key", catapi.WithBaseURL(server.URL+"/v1"), catapi.WithRetry(fastRetry))
+
+	start := time.Now()
+	_, err := client.ListBreeds(context.Background())
+	assert.NoError(t, err)
+	assert.GreaterOrEqual(t, time.Since(start), time.Second)
+	assert.Equal(t, int32(2), requests.Load())
+
+	// A Retry-After beyond the policy's limit is not waited out
+	header = http.Header{"Retry-After": []string{"60"}}
+	server, requests = newFailingServer(t, 1, http.StatusTooManyRequests, header)
+	client = catapi.New("test-api-key", catapi.WithBaseURL(server.URL+"/v1"), catapi.WithRetry(fastRetry))
+
+	start = time.Now()
+	_, err = client.ListBreeds(context.Background())
+	assert.Error(t, err)
+	assert.Less(t, time.Since(start), time.Second)
+	assert.Equal(t, int32(1), requests.Load())
+}
+
+func TestCatAPIClient_CircuitBreaker(t *testing.T) {
+	var mu sync.Mutex
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := func() time.Time {
+		mu.Lock()
+		defer mu.Unlock()
+		return now
+	}
+	advance := func(d time.Duration) {
+		mu.Lock()
+		defer mu.Unlock()
+		now = now.Add(d)
+	}
+
+	server, requests := newFailingServer(t, 3, http.StatusInternalServerError, nil)
+	client := catapi.New("test-api-key",
+		catapi.WithBaseURL(server.URL+"/v1"),
+		catapi.WithRetry(catapi.RetryPolicy{MaxAttempts: 1}),
+		catapi.WithCircuitBreaker(2, time.Minute),
+		catapi.WithClock(clock),
+	)
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		_, err := client.ListBreeds(ctx)
+		assert.Error(t, err)
+		assert.False(t, errors.Is(err, catapi.ErrCircuitOpen))
+	}
+	assert.Equal(t, catapi.BreakerOpen, client.Stats().Breaker.State)
+
+	// Open: fail fast without contacting the upstream
+	_, err := client.ListBreeds(ctx)
+	assert.ErrorIs(t, err, catapi.ErrCircuitOpen)
+	assert.Equal(t, int32(2), requests.Load())
+
+	// Failed trial after the cooldown re-opens the breaker
+	advance(time.Minute)
+	_, err = client.ListBreeds(ctx)
+	assert.Error(t, err)
+	assert.Equal(t, int32(3), requests.Load())
+	assert.Equal(t, catapi.BreakerOpen, client.Stats().Breaker.State)
+	_, err = client.ListBreeds(ctx)
+	assert.ErrorIs(t, err, catapi.ErrCircuitOpen)
+
+	// Successful trial closes it again
+	advance(time.Minute)
+	_, err = client.ListBreeds(ctx)
+	assert.NoError(t, err)
+
+	stats := client.Stats().Breaker
+	assert.Equal(t, catapi.BreakerClosed, stats.State)
+	assert.Equal(t, 0, stats.ConsecutiveFailures)
+	assert.Equal(t, int64(2), stats.Opens)
+	assert.Equal(t, int64(2), stats.Rejected)
+	assert.True(t, stats.OpenedAt.IsZero())
+}
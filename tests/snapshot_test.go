@@ -0,0 +1,154 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/beego/beego/v2/server/web"
+	beecontext "github.com/beego/beego/v2/server/web/context"
+	"github.com/stretchr/testify/assert"
+
+	"myproject/catapi"
+	"myproject/controllers"
+	"myproject/snapshot"
+)
+
+// withConfig sets an app.conf key for the duration of the test
+func withConfig(t *testing.T, key, value string) {
+	old := web.AppConfig.DefaultString(key, "")
+	assert.NoError(t, web.AppConfig.Set(key, value))
+	t.Cleanup(func() { web.AppConfig.Set(key, old) })
+}
+
+// writeSnapshot saves snap to a temporary file and returns its path
+func writeSnapshot(t *testing.T, snap snapshot.Snapshot) string {
+	data, err := json.Marshal(snap)
+	assert.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "breeds.json")
+	assert.NoError(t, os.WriteFile(path, data, 0644))
+	return path
+}
+
+func TestSnapshot_TakeAndLoad(t *testing.T) {
+	_, client := newFakeCatAPI(t)
+
+	snap, err := snapshot.Take(context.Background(), client)
+	assert.NoError(t, err)
+	assert.Equal(t, snapshot.Version, snap.Version)
+	assert.Equal(t, client.BaseURL(), snap.Source)
+	assert.Len(t, snap.Breeds, 10)
+	for _, b := range snap.Breeds {
+		if assert.NotNil(t, b.Image, b.ID) {
+			assert.Equal(t, b.ReferenceImageID, b.Image.ID)
+		}
+	}
+
+	loaded, err := snapshot.Load(writeSnapshot(t, *snap))
+	assert.NoError(t, err)
+	assert.Equal(t, snap.Breeds, loaded.Breeds)
+	assert.True(t, snap.TakenAt.Equal(loaded.TakenAt))
+}
+
+func TestSnapshot_DecodeRejectsOtherVersions(t *testing.T) {
+	_, err := snapshot.Decode(strings.NewReader(`{"version": 2, "breeds": []}`))
+	assert.ErrorContains(t, err, "unsupported version 2")
+
+	_, err = snapshot.Decode(strings.NewReader(`{"breeds": []}`))
+	assert.ErrorContains(t, err, "unsupported version 0")
+
+	_, err = snapshot.Decode(strings.NewReader(`not json`))
+	assert.Error(t, err)
+
+	// Whatever is embedded must at least decode
+	_, err = snapshot.Embedded()
+	assert.NoError(t, err)
+}
+
+func TestSnapshot_Check(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	snap := snapshot.Snapshot{Version: snapshot.Version, TakenAt: now.Add(-48 * time.Hour)}
+	assert.ErrorContains(t, snap.Check(now, 0), "no breeds")
+
+	snap.Breeds = []catapi.Breed{{ID: "abys", Name: "Abyssinian"}}
+	assert.NoError(t, snap.Check(now, 0))
+	assert.NoError(t, snap.Check(now, 72*time.Hour))
+	assert.ErrorContains(t, snap.Check(now, 24*time.Hour), "older than 24h0m0s")
+}
+
+// newDownBreedSearch returns a controller whose upstream always fails,
+// with the breed snapshot read from a file holding abys.
+func newDownBreedSearch(t *testing.T, method, target string, takenAt time.Time) (*controllers.BreedSearchController, *httptest.ResponseRecorder) {
+	withConfig(t, "breed_snapshot_file", writeSnapshot(t, snapshot.Snapshot{
+		Version: snapshot.Version,
+		TakenAt: takenAt,
+		Breeds: []catapi.Breed{{
+			ID: "abys", Name: "Abyssinian", Origin: "Egypt",
+			Image: &catapi.Image{ID: "0XYvRd7oD", URL: "https://cdn2.thecatapi.com/images/0XYvRd7oD.jpg"},
+		}},
+	}))
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "upstream down", http.StatusServiceUnavailable)
+	}))
+	t.Cleanup(server.Close)
+	client := catapi.New("test-api-key",
+		catapi.WithBaseURL(server.URL+"/v1"),
+		catapi.WithRetry(catapi.RetryPolicy{MaxAttempts: 1}),
+	)
+	controller := &controllers.BreedSearchController{APIKey: "test-api-key", API: client}
+
+	r, _ := http.NewRequest(method, target, nil)
+	w := httptest.NewRecorder()
+	ctx := beecontext.NewContext()
+	ctx.Reset(w, r)
+	controller.Init(ctx, "", "", controller)
+	return controller, w
+}
+
+func TestBreedSearchController_GetFromSnapshot(t *testing.T) {
+	takenAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	controller, w := newDownBreedSearch(t, "GET", "/breed-search", takenAt)
+	controller.Get()
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "STALE", w.Header().Get("X-Cache"))
+	assert.Equal(t, `110 - "Response is Stale"`, w.Header().Get("Warning"))
+	assert.Equal(t, "2024-05-01T12:00:00Z", w.Header().Get("X-Breeds-Snapshot"))
+
+	var breeds []catapi.Breed
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &breeds))
+	if assert.Len(t, breeds, 1) {
+		assert.Equal(t, "abys", breeds[0].ID)
+	}
+}
+
+func TestBreedSearchController_PostFromSnapshot(t *testing.T) {
+	takenAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	controller, w := newDownBreedSearch(t, "POST", "/breed-search?breed_id=abys", takenAt)
+	controller.Post()
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "STALE", w.Header().Get("X-Cache"))
+	assert.Equal(t, "2024-05-01T12:00:00Z", w.Header().Get("X-Breeds-Snapshot"))
+
+	var response struct {
+		Breed  catapi.Breed   `json:"breed"`
+		Images []catapi.Image `json:"images"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "Abyssinian", response.Breed.Name)
+	if assert.Len(t, response.Images, 1) {
+		assert.Equal(t, "0XYvRd7oD", response.Images[0].ID)
+	}
+
+	// A breed missing from the snapshot is still not found
+	controller, w = newDownBreedSearch(t, "POST", "/breed-search?breed_id=nope", takenAt)
+	controller.Post()
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
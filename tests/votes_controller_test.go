@@ -0,0 +1,349 @@
+package tests
+
+import (
+	stdcontext "context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"myproject/catapi"
+	"myproject/controllers"
+	"myproject/fakecatapi"
+	"myproject/models"
+)
+
+// getVotes calls VotesController.Get as userID
+func getVotes(t *testing.T, client *catapi.Client, userID, query string) (*httptest.ResponseRecorder, []controllers.VoteResponse) {
+	ctx, w := createTestContext("GET", "/votes?"+query)
+	withSession(ctx, userID)
+	controller := &controllers.VotesController{APIKey: "test-api-key", API: client}
+	controller.Init(ctx, "", "", controller)
+
+	controller.Get()
+
+	var votes []controllers.VoteResponse
+	if w.Code == http.StatusOK {
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &votes))
+	}
+	return w, votes
+}
+
+func TestVotesController_Get(t *testing.T) {
+	_, client := newFakeCatAPI(t)
+	ctx := stdcontext.Background()
+	for _, v := range []catapi.NewVote{
+		{ImageID: "abys-1", SubID: "user-1", Value: 1},
+		{ImageID: "abys-2", SubID: "user-1", Value: -1},
+		{ImageID: "abys-3", SubID: "user-2", Value: 1},
+		{ImageID: "abys-1", SubID: "user-1", Value: -1},
+	} {
+		_, err := client.CreateVote(ctx, v)
+		assert.NoError(t, err)
+	}
+
+	w, votes := getVotes(t, client, "user-1", "order=DESC&limit=2")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "3", w.Header().Get("Pagination-Count"))
+	if assert.Len(t, votes, 2) {
+		assert.Equal(t, "abys-1", votes[0].ImageID)
+		assert.Equal(t, -1, votes[0].Value)
+		assert.Equal(t, "abys-2", votes[1].ImageID)
+		assert.Contains(t, votes[1].Image.URL, "abys-2")
+	}
+
+	w, votes = getVotes(t, client, "user-1", "order=DESC&limit=2&page=1")
+	assert.Equal(t, http.StatusOK, w.Code)
+	if assert.Len(t, votes, 1) {
+		assert.Equal(t, 1, votes[0].Value)
+	}
+
+	// The image view lists every vote of the caller on one image
+	w, votes = getVotes(t, client, "user-1", "image_id=abys-1")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Header().Get("Pagination-Count"))
+	if assert.Len(t, votes, 2) {
+		assert.Equal(t, []int{1, -1}, []int{votes[0].Value, votes[1].Value})
+	}
+	_, votes = getVotes(t, client, "user-2", "image_id=abys-1")
+	assert.NotNil(t, votes)
+	assert.Empty(t, votes)
+}
+
+func TestVotesController_GetInvalid(t *testing.T) {
+	tests := []struct {
+		name       string
+		userID     string
+		query      string
+		wantStatus int
+		wantError  string
+	}{
+		{"logged out", "", "", http.StatusUnauthorized, "Login required"},
+		{"someone else", "user-1", "sub_id=user-2", http.StatusForbidden, "Cannot list another user's votes"},
+		{"bad page", "user-1", "page=-1", http.StatusBadRequest, "page must be a non-negative integer"},
+		{"bad limit", "user-1", "limit=101", http.StatusBadRequest, "limit must be between 1 and 100"},
+		{"bad order", "user-1", "order=RAND", http.StatusBadRequest, "order must be ASC or DESC"},
+	}
+
+	_, client := newFakeCatAPI(t)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, _ := getVotes(t, client, tt.userID, tt.query)
+			assert.Equal(t, tt.wantStatus, w.Code)
+			var response map[string]string
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, tt.wantError, response["error"])
+		})
+	}
+}
+
+func TestVotesController_GetJoinsImages(t *testing.T) {
+	var lookups atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/votes":
+			w.Write([]byte(`[
+				{"id": 1, "image_id": "a", "sub_id": "user-1", "value": 1, "image": {}},
+				{"id": 2, "image_id": "b", "sub_id": "user-1", "value": 1},
+				{"id": 3, "image_id": "a", "sub_id": "user-1", "value": -1},
+				{"id": 4, "image_id": "c", "sub_id": "user-1", "value": 1, "image": {"id": "c", "url": "https://example.com/c.jpg"}}
+			]`))
+		case "/v1/images/a":
+			lookups.Add(1)
+			w.Write([]byte(`{"id": "a", "url": "https://example.com/a.jpg"}`))
+		default:
+			lookups.Add(1)
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	client := catapi.New("test-api-key", catapi.WithBaseURL(server.URL+"/v1"), catapi.WithRetry(catapi.RetryPolicy{MaxAttempts: 1}))
+
+	w, votes := getVotes(t, client, "user-1", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	if assert.Len(t, votes, 4) {
+		assert.Equal(t, "https://example.com/a.jpg", votes[0].Image.URL)
+		assert.Empty(t, votes[1].Image.URL)
+		assert.Equal(t, "https://example.com/a.jpg", votes[2].Image.URL)
+		assert.Equal(t, "https://example.com/c.jpg", votes[3].Image.URL)
+	}
+	// One lookup per missing image
+	assert.Equal(t, int32(2), lookups.Load())
+}
+
+func TestVotesController_GetUpstreamError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "boom", http.StatusInternalServerError)
+	}))
+	defer server.Close()
+	client := catapi.New("test-api-key", catapi.WithBaseURL(server.URL+"/v1"), catapi.WithRetry(catapi.RetryPolicy{MaxAttempts: 1}))
+
+	w, _ := getVotes(t, client, "user-1", "")
+	assert.Equal(t, http.StatusBadGateway, w.Code)
+	assert.JSONEq(t, `{"error": "Failed to fetch votes"}`, w.Body.String())
+}
+
+// changeVote calls VotesController.Delete or Put for vote id as userID,
+// keeping local votes in votes or, when it is nil, in a throwaway store
+func changeVote(t *testing.T, client *catapi.Client, votes *models.VoteStore, method, userID, id, query string) (*httptest.ResponseRecorder, map[string]interface{}) {
+	ctx, w := createTestContext(method, "/votes/"+id+"?"+query)
+	ctx.Input.SetParam(":id", id)
+	withSession(ctx, userID)
+	if votes == nil {
+		votes, _ = models.NewVoteStore("")
+	}
+	controller := &controllers.VotesController{APIKey: "test-api-key", API: client, Votes: votes}
+	controller.Init(ctx, "", "", controller)
+
+	if method == "DELETE" {
+		controller.Delete()
+	} else {
+		controller.Put()
+	}
+
+	var response map[string]interface{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	return w, response
+}
+
+func TestVotesController_Delete(t *testing.T) {
+	_, client := newFakeCatAPI(t)
+	ctx := stdcontext.Background()
+	mine, err := client.CreateVote(ctx, catapi.NewVote{ImageID: "abys-1", SubID: "user-1", Value: -1})
+	assert.NoError(t, err)
+	theirs, err := client.CreateVote(ctx, catapi.NewVote{ImageID: "abys-1", SubID: "user-2", Value: 1})
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		id         string
+		wantStatus int
+		wantError  string
+	}{
+		{"bad id", "abc", http.StatusBadRequest, "Invalid vote ID"},
+		{"missing", "9999", http.StatusNotFound, "Vote not found"},
+		{"someone else's", strconv.Itoa(theirs.ID), http.StatusForbidden, "Vote belongs to another user"},
+		{"own", strconv.Itoa(mine.ID), http.StatusOK, ""},
+		{"already undone", strconv.Itoa(mine.ID), http.StatusNotFound, "Vote not found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, response := changeVote(t, client, nil, "DELETE", "user-1", tt.id, "")
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantError != "" {
+				assert.Equal(t, tt.wantError, response["error"])
+			} else {
+				assert.Equal(t, "deleted", response["status"])
+			}
+		})
+	}
+
+	_, votes := getVotes(t, client, "user-1", "")
+	assert.Empty(t, votes)
+	_, votes = getVotes(t, client, "user-2", "")
+	assert.Len(t, votes, 1)
+}
+
+func TestVotesController_Put(t *testing.T) {
+	_, client := newFakeCatAPI(t)
+	created, err := client.CreateVote(stdcontext.Background(), catapi.NewVote{ImageID: "abys-1", SubID: "user-1", Value: -1})
+	assert.NoError(t, err)
+	id := strconv.Itoa(created.ID)
+
+	w, response := changeVote(t, client, nil, "PUT", "user-1", id, "value=maybe")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, "value must be like or dislike", response["error"])
+
+	w, _ = changeVote(t, client, nil, "PUT", "user-2", id, "value=like")
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	// The dislike is replaced by a like
+	w, response = changeVote(t, client, nil, "PUT", "user-1", id, "value=like")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, float64(created.ID), response["replaced"])
+	assert.Equal(t, float64(1), response["value"])
+	_, votes := getVotes(t, client, "user-1", "")
+	if assert.Len(t, votes, 1) {
+		assert.Equal(t, 1, votes[0].Value)
+		assert.Equal(t, int(response["id"].(float64)), votes[0].ID)
+	}
+
+	// Asking for the current value changes nothing
+	newID := strconv.Itoa(votes[0].ID)
+	w, response = changeVote(t, client, nil, "PUT", "user-1", newID, "value=like")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, float64(votes[0].ID), response["id"])
+	assert.NotContains(t, response, "replaced")
+}
+
+func TestVotesController_PutRollsBack(t *testing.T) {
+	fake := fakecatapi.New(fakecatapi.WithSeed(1))
+	var failDelete atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Removing the old vote fails; taking back the new one works
+		if r.Method == "DELETE" && failDelete.CompareAndSwap(true, false) {
+			http.Error(w, "boom", http.StatusInternalServerError)
+			return
+		}
+		fake.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+	client := catapi.New("test-api-key", catapi.WithBaseURL(server.URL+"/v1"), catapi.WithRetry(catapi.RetryPolicy{MaxAttempts: 1}))
+
+	created, err := client.CreateVote(stdcontext.Background(), catapi.NewVote{ImageID: "abys-1", SubID: "user-1", Value: -1})
+	assert.NoError(t, err)
+
+	failDelete.Store(true)
+	w, response := changeVote(t, client, nil, "PUT", "user-1", strconv.Itoa(created.ID), "value=like")
+	assert.Equal(t, http.StatusBadGateway, w.Code)
+	assert.Equal(t, "Failed to change vote", response["error"])
+
+	// The user is left with the original vote only
+	_, votes := getVotes(t, client, "user-1", "")
+	if assert.Len(t, votes, 1) {
+		assert.Equal(t, created.ID, votes[0].ID)
+		assert.Equal(t, -1, votes[0].Value)
+	}
+}
+
+// newStaleReadServer serves fake, holding the first two vote lookups until
+// both have arrived so that concurrent requests read the same vote.
+func newStaleReadServer(t *testing.T, fake http.Handler) *catapi.Client {
+	var lookups atomic.Int32
+	both := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "GET" && strings.HasPrefix(r.URL.Path, "/v1/votes/") {
+			switch lookups.Add(1) {
+			case 1:
+				// Identical lookups may be coalesced into this one
+				select {
+				case <-both:
+				case <-time.After(200 * time.Millisecond):
+				}
+			case 2:
+				close(both)
+			}
+		}
+		fake.ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+	return catapi.New("test-api-key", catapi.WithBaseURL(server.URL+"/v1"), catapi.WithRetry(catapi.RetryPolicy{MaxAttempts: 1}))
+}
+
+// concurrently runs the vote changes in methods and queries on vote id at
+// the same time and returns their status codes in order.
+func concurrently(t *testing.T, client *catapi.Client, id string, methods, queries []string) []int {
+	codes := make([]int, len(methods))
+	var wg sync.WaitGroup
+	for i := range methods {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w, _ := changeVote(t, client, nil, methods[i], "user-1", id, queries[i])
+			codes[i] = w.Code
+		}()
+	}
+	wg.Wait()
+	return codes
+}
+
+func TestVotesController_ConcurrentPuts(t *testing.T) {
+	client := newStaleReadServer(t, fakecatapi.New(fakecatapi.WithSeed(1)))
+	created, err := client.CreateVote(stdcontext.Background(), catapi.NewVote{ImageID: "abys-1", SubID: "user-1", Value: -1})
+	assert.NoError(t, err)
+
+	codes := concurrently(t, client, strconv.Itoa(created.ID), []string{"PUT", "PUT"}, []string{"value=like", "value=like"})
+
+	// One replaces the dislike; the other finds it gone
+	assert.ElementsMatch(t, []int{http.StatusOK, http.StatusNotFound}, codes)
+	_, votes := getVotes(t, client, "user-1", "")
+	if assert.Len(t, votes, 1) {
+		assert.Equal(t, 1, votes[0].Value)
+	}
+}
+
+func TestVotesController_PutRacingDelete(t *testing.T) {
+	client := newStaleReadServer(t, fakecatapi.New(fakecatapi.WithSeed(1)))
+	created, err := client.CreateVote(stdcontext.Background(), catapi.NewVote{ImageID: "abys-1", SubID: "user-1", Value: -1})
+	assert.NoError(t, err)
+
+	codes := concurrently(t, client, strconv.Itoa(created.ID), []string{"PUT", "DELETE"}, []string{"value=like", ""})
+
+	// Whichever comes second finds the vote gone, so an undo never
+	// brings the vote back
+	_, votes := getVotes(t, client, "user-1", "")
+	if codes[0] == http.StatusOK {
+		assert.Equal(t, http.StatusNotFound, codes[1])
+		assert.Len(t, votes, 1)
+	} else {
+		assert.Equal(t, []int{http.StatusNotFound, http.StatusOK}, codes)
+		assert.Empty(t, votes)
+	}
+}
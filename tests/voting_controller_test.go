@@ -1,19 +1,27 @@
 package tests
 
 import (
+	stdcontext "context"
+	"encoding/json"
+	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	beego "github.com/beego/beego/v2/server/web"
 	"github.com/beego/beego/v2/server/web/context"
 	"github.com/stretchr/testify/assert"
 
+	"myproject/catapi"
 	"myproject/controllers"
+	"myproject/models"
 )
 
 func init() {
@@ -89,6 +97,10 @@ func initController(ctx *context.Context) *controllers.VotingController {
 	controller.Init(ctx, "", "", nil)
 	controller.Data = make(map[interface{}]interface{})
 	controller.APIKey = "test_api_key"
+	// Keep votes in memory rather than in the configured vote store
+	controller.Votes, _ = models.NewVoteStore("")
+	// Fetch every image directly rather than from a background-filled pool
+	controller.Pool = catapi.NewImagePool(nil, 0, 0)
 	return controller
 }
 
@@ -136,3 +148,180 @@ func TestMain(m *testing.M) {
 
 	os.Exit(code)
 }
+
+// newEchoImageServer answers every images/search call with an image whose
+// ID is the caller's API key, so each client can recognise its own result.
+func newEchoImageServer(t *testing.T) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get("x-api-key")
+		w.Write([]byte(`[{"id": "` + key + `", "url": "https://example.com/` + key + `.jpg"}]`))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestVotingControllerGet_ConcurrentRequestsDoNotCrossTalk(t *testing.T) {
+	server := newEchoImageServer(t)
+
+	const requests = 300
+	var wg sync.WaitGroup
+	for i := 0; i < requests; i++ {
+		wg.Add(1)
+		go func(key string) {
+			defer wg.Done()
+
+			ctx, w := createTestContext("GET", "/voting")
+			controller := initController(ctx)
+			controller.API = catapi.New(key, catapi.WithBaseURL(server.URL))
+
+			controller.Get()
+
+			var body map[string]string
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+			assert.Equal(t, key, body["image_id"])
+			assert.Equal(t, "https://example.com/"+key+".jpg", body["image_url"])
+		}(fmt.Sprintf("client-%d", i))
+	}
+	wg.Wait()
+}
+
+func TestVotingControllerGet_SharedClientGetsDistinctImages(t *testing.T) {
+	var requests atomic.Int32
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := requests.Add(1)
+		<-release
+		fmt.Fprintf(w, `[{"id": "img-%d", "url": "https://example.com/img-%d.jpg"}]`, n, n)
+	}))
+	defer server.Close()
+	client := catapi.New("test_api_key", catapi.WithBaseURL(server.URL))
+
+	const callers = 20
+	var wg sync.WaitGroup
+	ids := make(chan string, callers)
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctx, w := createTestContext("GET", "/voting")
+			controller := initController(ctx)
+			controller.API = client
+			controller.Get()
+
+			var body map[string]string
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+			ids <- body["image_id"]
+		}()
+	}
+
+	// Every caller is in flight at once, so coalescing would merge them
+	waitFor(t, func() bool { return requests.Load() == callers })
+	close(release)
+	wg.Wait()
+	close(ids)
+
+	seen := map[string]bool{}
+	for id := range ids {
+		assert.False(t, seen[id], "image %s served twice", id)
+		seen[id] = true
+	}
+	assert.Len(t, seen, callers)
+	assert.Zero(t, client.Stats().Coalesced)
+}
+
+func TestVotingControllerGet_ClientDisconnect(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	ctx, w := createTestContext("GET", "/voting")
+	reqCtx, cancel := stdcontext.WithCancel(ctx.Request.Context())
+	ctx.Request = ctx.Request.WithContext(reqCtx)
+	controller := initController(ctx)
+	controller.API = catapi.New("test_api_key", catapi.WithBaseURL(server.URL))
+
+	done := make(chan struct{})
+	go func() {
+		controller.Get()
+		close(done)
+	}()
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Get did not return after the client disconnected")
+	}
+	assert.Empty(t, w.Body.String())
+}
+
+func TestVotingControllerPost(t *testing.T) {
+	var votes []string
+	var mu sync.Mutex
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/votes":
+			body, _ := io.ReadAll(r.Body)
+			mu.Lock()
+			votes = append(votes, string(body))
+			mu.Unlock()
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"message": "SUCCESS", "id": 1}`))
+		case "/favourites":
+			http.Error(w, "DUPLICATE_FAVOURITE", http.StatusBadRequest)
+		default:
+			w.Write([]byte(`[{"id": "next", "url": "https://example.com/next.jpg"}]`))
+		}
+	}))
+	defer server.Close()
+
+	// Votes come back with their ID and how long they may be undone
+	voted := map[string]interface{}{
+		"image_id": "next", "image_url": "https://example.com/next.jpg",
+		"vote_id": float64(1), "undo_seconds": float64(10),
+	}
+	tests := []struct {
+		name     string
+		action   string
+		expected map[string]interface{}
+	}{
+		{"like", "like", voted},
+		{"dislike", "dislike", voted},
+		{"favorite fails", "favorite", map[string]interface{}{"error": "Failed to favorite the image"}},
+		{"unknown action", "share", map[string]interface{}{"error": "Unknown action"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, w := createTestContext("POST", "/voting?action="+tt.action+"&image_id=img1")
+			withSession(ctx, "user-1")
+			controller := initController(ctx)
+			controller.API = catapi.New("test_api_key", catapi.WithBaseURL(server.URL))
+
+			controller.Post()
+
+			var body map[string]interface{}
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+			assert.Equal(t, tt.expected, body)
+		})
+	}
+
+	assert.Len(t, votes, 2)
+	assert.Contains(t, votes[0], `"value":1`)
+	assert.Contains(t, votes[1], `"value":-1`)
+	assert.Contains(t, votes[0], `"sub_id":"user-1"`)
+}
+
+func TestVotingControllerPost_RequiresLogin(t *testing.T) {
+	ctx, w := createTestContext("POST", "/voting?action=like&image_id=img1")
+	withSession(ctx, "")
+	controller := initController(ctx)
+
+	controller.Post()
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.JSONEq(t, `{"error": "Login required"}`, w.Body.String())
+}
@@ -0,0 +1,65 @@
+<!DOCTYPE html>
+<html lang="{{.Lang}}">
+<head>
+    <meta charset="UTF-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+    <title>{{.Breed.Name}} - {{t .Lang "title"}}</title>
+    <meta name="description" content="{{.OGDescription}}">
+    {{if .PageURL}}<link rel="canonical" href="{{.PageURL}}">{{end}}
+    <meta property="og:type" content="article">
+    <meta property="og:site_name" content="{{t .Lang "title"}}">
+    <meta property="og:title" content="{{.Breed.Name}}">
+    <meta property="og:description" content="{{.OGDescription}}">
+    {{if .PageURL}}<meta property="og:url" content="{{.PageURL}}">{{end}}
+    {{if .OGImage}}<meta property="og:image" content="{{.OGImage}}">
+    <meta name="twitter:card" content="summary_large_image">{{else}}<meta name="twitter:card" content="summary">{{end}}
+    <link rel="stylesheet" href="/static/css/main.css">
+    <link rel="stylesheet" href="/static/css/breed_search.css">
+    <link rel="stylesheet" href="/static/css/breed_page.css">
+</head>
+<body>
+    <div class="content-container breed-page">
+        <nav class="nav-tabs">
+            <a href="/#voting" class="nav-item">{{t .Lang "nav.voting"}}</a>
+            <a href="/#breeds" class="nav-item active">{{t .Lang "nav.breeds"}}</a>
+            <a href="/#favorites" class="nav-item">{{t .Lang "nav.favorites"}}</a>
+        </nav>
+
+        {{if .Stale}}<p class="stale-notice">{{if .SnapshotAt}}{{printf (t .Lang "breed.stale_saved") .SnapshotAt}}{{else}}{{t .Lang "breed.stale"}}{{end}}</p>{{end}}
+
+        <article class="breed-info">
+            <h1 class="breed-title">{{.Breed.Name}} <span class="breed-id">{{.Breed.ID}}</span></h1>
+            <p class="breed-description">{{.Breed.Description}}</p>
+
+            <dl class="breed-facts">
+                {{range .Facts}}<dt>{{.Label}}</dt><dd>{{.Value}}</dd>
+                {{end}}
+            </dl>
+
+            {{if .Flags}}<ul class="breed-flags">
+                {{range .Flags}}<li>{{.}}</li>
+                {{end}}
+            </ul>{{end}}
+
+            {{if .Ratings}}<ul class="breed-ratings">
+                {{range .Ratings}}<li>
+                    <span class="rating-label">{{.Label}}</span>
+                    <span class="rating-bar"><span style="width: {{.Percent}}%"></span></span>
+                    <span class="rating-value">{{.Level}}/5</span>
+                </li>
+                {{end}}
+            </ul>{{end}}
+
+            {{if .Breed.WikipediaURL}}<a href="{{.Breed.WikipediaURL}}" target="_blank" rel="noopener" class="wiki-link">{{t .Lang "breed.wikipedia"}}</a>{{end}}
+        </article>
+
+        {{if .Images}}<section class="breed-gallery">
+            <h2>{{t .Lang "breed.gallery"}}</h2>
+            <ul>
+                {{range .Images}}<li><img src="{{.URL}}" alt="{{$.Breed.Name}}" loading="lazy"></li>
+                {{end}}
+            </ul>
+        </section>{{end}}
+    </div>
+</body>
+</html>
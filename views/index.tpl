@@ -1,15 +1,18 @@
 <!DOCTYPE html>
-<html lang="en">
+<html lang="{{.Lang}}">
 <head>
     <meta charset="UTF-8">
     <meta name="viewport" content="width=device-width, initial-scale=1.0">
-    <title>Cat Browser</title>
+    <title>{{t .Lang "title"}}</title>
     <link rel="stylesheet" href="https://unpkg.com/swiper/swiper-bundle.min.css" />
     <link href="https://cdnjs.cloudflare.com/ajax/libs/font-awesome/6.0.0-beta3/css/all.min.css" rel="stylesheet">
     <link rel="stylesheet" href="/static/css/main.css">
     <link rel="stylesheet" href="/static/css/voting.css">
     <link rel="stylesheet" href="/static/css/breed_search.css">
     <link rel="stylesheet" href="/static/css/favs.css"></link>
+    <link rel="stylesheet" href="/static/css/auth.css">
+    <link rel="stylesheet" href="/static/css/leaderboard.css">
+    <link rel="stylesheet" href="/static/css/duel.css">
 </head>
 <body>
     <div class="content-container">
@@ -22,40 +25,98 @@
                 <svg width="24" height="24" viewBox="0 0 24 24" fill="none" stroke="currentColor" stroke-width="2">
                     <path d="M12 4v16M4 12l8 8 8-8"/>
                 </svg>
-                Voting
+                {{t .Lang "nav.voting"}}
             </a>
             <a href="#breeds" class="nav-item" data-page="breeds">
                 <svg width="24" height="24" viewBox="0 0 24 24" fill="none" stroke="currentColor" stroke-width="2">
                     <circle cx="11" cy="11" r="8"/>
                     <path d="M21 21l-4.35-4.35"/>
                 </svg>
-                Breeds
+                {{t .Lang "nav.breeds"}}
             </a>
             <a href="#favorites" class="nav-item" data-page="favorites">
                 <svg width="24" height="24" viewBox="0 0 24 24" fill="none" stroke="currentColor" stroke-width="2">
                     <path d="M20.84 4.61a5.5 5.5 0 0 0-7.78 0L12 5.67l-1.06-1.06a5.5 5.5 0 0 0-7.78 7.78L12 21.23l8.84-8.84a5.5 5.5 0 0 0 0-7.78z"/>
                 </svg>
-                Favs
+                {{t .Lang "nav.favorites"}}
             </a>
+            <a href="#leaderboard" class="nav-item" data-page="leaderboard">
+                <svg width="24" height="24" viewBox="0 0 24 24" fill="none" stroke="currentColor" stroke-width="2">
+                    <path d="M8 21h8M12 17v4M7 4h10v5a5 5 0 0 1-10 0V4zM7 6H4a3 3 0 0 0 3 4M17 6h3a3 3 0 0 1-3 4"/>
+                </svg>
+                {{t .Lang "nav.leaderboard"}}
+            </a>
+            <nav class="language-switcher" aria-label="{{t .Lang "language"}}">
+                {{range .Languages}}<a href="/?lang={{.}}" hreflang="{{.}}" lang="{{.}}"{{if eq . $.Lang}} class="active"{{end}}>{{index $.LanguageNames .}}</a>
+                {{end}}
+            </nav>
+            <div class="account">
+                <form id="auth-form" class="auth-form">
+                    <input type="text" id="auth-username" placeholder="{{t .Lang "auth.username"}}" autocomplete="username" required>
+                    <input type="password" id="auth-password" placeholder="{{t .Lang "auth.password"}}" autocomplete="current-password" required>
+                    <button type="submit" data-action="login">{{t .Lang "auth.login"}}</button>
+                    <button type="submit" data-action="register">{{t .Lang "auth.register"}}</button>
+                </form>
+                <div id="account-info" class="account-info">
+                    <span id="account-name"></span>
+                    <button id="logout-btn">{{t .Lang "auth.logout"}}</button>
+                </div>
+            </div>
         </nav>
+        <p id="auth-message" class="auth-message"></p>
 
         <!-- Content sections -->
         <div id="voting-content" class="page-content">
-            <h1>Vote for a Cat!</h1>
-            <div class="image-container">
-                <img id="voting-image" src="">
+            <h1>{{t .Lang "voting.heading"}}</h1>
+            <div id="voting-modes" class="voting-modes">
+                <button data-mode="single" class="active">{{t .Lang "voting.single"}}</button>
+                <button data-mode="duel">{{t .Lang "voting.duel"}}</button>
             </div>
-            <div class="buttons-container">
-                <button class="heart-icon" onclick="handleFavorite()">❤</button>
-                <div class="like-dislike-container">
-                    <button class="like-button" onclick="handleVote('like')">👍</button>
-                    <button class="dislike-button" onclick="handleVote('dislike')">👎</button>
+            <div id="single-mode">
+                <div class="image-container">
+                    <img id="voting-image" src="">
+                </div>
+                <div class="buttons-container">
+                    <button class="heart-icon" onclick="handleFavorite()">❤</button>
+                    <div class="like-dislike-container">
+                        <button class="like-button" onclick="handleVote('like')">👍</button>
+                        <button class="dislike-button" onclick="handleVote('dislike')">👎</button>
+                    </div>
+                </div>
+                <div id="vote-undo" class="vote-undo" hidden>
+                    <span>{{t .Lang "voting.saved"}}</span>
+                    <button id="vote-undo-btn">{{t .Lang "voting.undo"}}</button>
+                    <button id="vote-change-btn">{{t .Lang "voting.change"}}</button>
                 </div>
             </div>
+            <div id="duel-mode" hidden>
+                <p class="duel-prompt">{{t .Lang "duel.prompt"}}</p>
+                <div id="duel-images" class="duel-images">
+                    <!-- The two contenders will be populated by JavaScript -->
+                </div>
+                <h2>{{t .Lang "duel.ranking"}}</h2>
+                <ol id="duel-ranking" class="leaderboard-list">
+                    <!-- Top rated images will be populated by JavaScript -->
+                </ol>
+            </div>
+            <section class="vote-history">
+                <h2>{{t .Lang "history.heading"}}</h2>
+                <ul id="vote-history-list">
+                    <!-- Votes will be populated by JavaScript -->
+                </ul>
+                <div id="vote-history-pager" class="vote-history-pager">
+                    <button id="history-prev-btn"><i class="fas fa-chevron-left"></i></button>
+                    <span id="history-page-info"></span>
+                    <button id="history-next-btn"><i class="fas fa-chevron-right"></i></button>
+                </div>
+            </section>
         </div>
 
         <div id="breeds-content" class="page-content">
+            <p id="breeds-stale" class="stale-notice" hidden></p>
             <div class="search-container">
+                <input type="search" id="breed-search-input" placeholder="{{t .Lang "breeds.search"}}" autocomplete="off">
+                <ul id="breed-suggestions" class="breed-suggestions"></ul>
                 <select id="breed-select">
                     <!-- Breeds will be populated by JavaScript -->
                 </select>
@@ -66,15 +127,62 @@
                 </div>
                 <div class="swiper-pagination"></div>
             </div>
+            <button id="gallery-more-btn" class="gallery-more-btn">{{t .Lang "breeds.more_photos"}}</button>
             <div class="breed-info">
                 <h2 class="breed-title"></h2>
                 <p class="breed-description"></p>
+                <div class="breed-profile">
+                    <dl class="breed-facts">
+                        <!-- Temperament, life span and weight will be populated by JavaScript -->
+                    </dl>
+                    <ul class="breed-flags"></ul>
+                    <ul class="breed-ratings"></ul>
+                </div>
                 <a href="#" target="_blank" class="wiki-link">WIKIPEDIA</a>
             </div>
+            <div class="breed-origins">
+                <h3>{{t .Lang "breeds.origins"}}</h3>
+                <ul id="origin-map" class="origin-map">
+                    <!-- Countries will be populated by JavaScript -->
+                </ul>
+            </div>
+            <form id="breed-matcher" class="breed-matcher">
+                <h3>{{t .Lang "matcher.heading"}}</h3>
+                <label>{{t .Lang "matcher.home"}}
+                    <select name="apartment_size">
+                        <option value="">{{t .Lang "matcher.no_preference"}}</option>
+                        <option value="small">{{t .Lang "matcher.small"}}</option>
+                        <option value="medium">{{t .Lang "matcher.medium"}}</option>
+                        <option value="large">{{t .Lang "matcher.large"}}</option>
+                    </select>
+                </label>
+                <label>{{t .Lang "matcher.time_at_home"}}
+                    <select name="time_at_home">
+                        <option value="">{{t .Lang "matcher.no_preference"}}</option>
+                        <option value="little">{{t .Lang "matcher.little"}}</option>
+                        <option value="some">{{t .Lang "matcher.some"}}</option>
+                        <option value="lots">{{t .Lang "matcher.lots"}}</option>
+                    </select>
+                </label>
+                <label><input type="checkbox" name="allergies" value="true"> {{t .Lang "matcher.allergies"}}</label>
+                <label><input type="checkbox" name="kids" value="true"> {{t .Lang "matcher.kids"}}</label>
+                <label><input type="checkbox" name="other_pets" value="dogs"> {{t .Lang "matcher.dogs"}}</label>
+                <label><input type="checkbox" name="other_pets" value="cats"> {{t .Lang "matcher.cats"}}</label>
+                <button type="submit">{{t .Lang "matcher.submit"}}</button>
+                <ol id="matcher-results"></ol>
+            </form>
+            <div class="breed-compare">
+                <div class="compare-tray">
+                    <button id="compare-add-btn">{{t .Lang "compare.add"}}</button>
+                    <ul id="compare-selection"></ul>
+                    <button id="compare-run-btn" disabled>{{t .Lang "compare.run"}}</button>
+                </div>
+                <div id="compare-result"></div>
+            </div>
         </div>
 
         <div id="favorites-content" class="page-content">
-            <h1>Your Favorite Cat Images</h1>
+            <h1>{{t .Lang "favorites.heading"}}</h1>
             <div class="favorites-container">
                 <div id="favorites-controls">
                     <button id="grid-view-btn"><i class="fas fa-th"></i></button>
@@ -83,12 +191,37 @@
                 <ul id="favorites-list">
                     <!-- Favorites will be populated by JavaScript -->
                 </ul>
+                <div id="favorites-pager" class="favorites-pager">
+                    <button id="fav-prev-btn"><i class="fas fa-chevron-left"></i></button>
+                    <span id="fav-page-info"></span>
+                    <button id="fav-next-btn"><i class="fas fa-chevron-right"></i></button>
+                </div>
+            </div>
+        </div>
+
+        <div id="leaderboard-content" class="page-content">
+            <h1>{{t .Lang "leaderboard.heading"}}</h1>
+            <div id="leaderboard-windows" class="leaderboard-windows">
+                <button data-window="day">{{t .Lang "leaderboard.day"}}</button>
+                <button data-window="week" class="active">{{t .Lang "leaderboard.week"}}</button>
+                <button data-window="all">{{t .Lang "leaderboard.all"}}</button>
             </div>
+            <ol id="leaderboard-list" class="leaderboard-list">
+                <!-- Top images will be populated by JavaScript -->
+            </ol>
         </div>
     </div>
 
     <script src="https://unpkg.com/swiper/swiper-bundle.min.js"></script>
+    <script src="/static/js/auth.js"></script>
     <script src="/static/js/spa.js"></script>
     <script src="/static/js/fav_view.js"></script>
+    <script src="/static/js/vote_history.js"></script>
+    <script src="/static/js/vote_undo.js"></script>
+    <script src="/static/js/leaderboard.js"></script>
+    <script src="/static/js/duel.js"></script>
+    <script src="/static/js/breed_compare.js"></script>
+    <script src="/static/js/breed_matcher.js"></script>
+    <script src="/static/js/breed_origins.js"></script>
 </body>
 </html>
\ No newline at end of file